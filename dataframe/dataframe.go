@@ -3,10 +3,15 @@
 package dataframe
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math"
+	"math/rand"
 	"reflect"
 	"sort"
 	"strconv"
@@ -16,6 +21,7 @@ import (
 	"github.com/netxops/frame/series"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
+	"gonum.org/v1/gonum/stat"
 )
 
 // DataFrame is a data structure designed for operating on table like data (Such
@@ -34,6 +40,7 @@ type DataFrame struct {
 	columns []series.Series
 	ncols   int
 	nrows   int
+	index   *rowIndex
 
 	// deprecated: Use Error() instead
 	Err error
@@ -91,12 +98,15 @@ func checkColumnsDimensions(se ...series.Series) (nrows, ncols int, err error) {
 	return
 }
 
-// Copy returns a copy of the DataFrame
+// Copy returns a copy of the DataFrame, including its row index if any
 func (df DataFrame) Copy() DataFrame {
 	copy := New(df.columns...)
 	if df.Err != nil {
 		copy.Err = df.Err
 	}
+	if df.index != nil && copy.Err == nil {
+		copy.index = newRowIndex(df.index.column, copy.columns[copy.colIndex(df.index.column)])
+	}
 	return copy
 }
 
@@ -105,6 +115,184 @@ func (df DataFrame) String() (str string) {
 	return df.print(true, true, true, true, 10, 70, "DataFrame")
 }
 
+// PrintOptions configures the output of DataFrame.Print. A zero value on any
+// of the limits means no limit.
+type PrintOptions struct {
+	// MaxRows is the maximum number of rows shown.
+	MaxRows int
+	// MaxColWidth is the maximum number of characters shown per cell. Longer
+	// cells are cut and end with "...".
+	MaxColWidth int
+	// MaxCols is the maximum number of columns shown. The remaining ones are
+	// summarized as "... (K more cols)".
+	MaxCols int
+}
+
+// Print returns a table representation of the DataFrame in the same layout as
+// String, limited to the rows, columns and cell width given in opts.
+func (df DataFrame) Print(opts PrintOptions) string {
+	if df.Err != nil {
+		return fmt.Sprintf("DataFrame error: %v", df.Err)
+	}
+	nrows, ncols := df.Dims()
+	if nrows == 0 || ncols == 0 {
+		return "Empty DataFrame"
+	}
+
+	sub := df
+	shortRows := opts.MaxRows > 0 && nrows > opts.MaxRows
+	if shortRows {
+		sub = sub.Subset(series.Ints(seq(opts.MaxRows)))
+	}
+	hiddenCols := 0
+	if opts.MaxCols > 0 && ncols > opts.MaxCols {
+		hiddenCols = ncols - opts.MaxCols
+		sub = sub.Select(seq(opts.MaxCols))
+	}
+
+	records := sub.Records()
+	for i := range records {
+		add := ""
+		if i != 0 {
+			add = strconv.Itoa(i-1) + ":"
+		}
+		records[i] = append([]string{add}, records[i]...)
+	}
+	if shortRows {
+		dots := make([]string, sub.ncols+1)
+		for i := 1; i < len(dots); i++ {
+			dots[i] = "..."
+		}
+		records = append(records, dots)
+	}
+	typesrow := []string{""}
+	for _, t := range sub.Types() {
+		typesrow = append(typesrow, fmt.Sprintf("<%v>", t))
+	}
+	records = append(records, typesrow)
+
+	maxChars := make([]int, sub.ncols+1)
+	for i := range records {
+		for j := range records[i] {
+			cell := strconv.Quote(records[i][j])
+			cell = cell[1 : len(cell)-1]
+			if j > 0 && opts.MaxColWidth > 0 && utf8.RuneCountInString(cell) > opts.MaxColWidth {
+				runes := []rune(cell)
+				if opts.MaxColWidth > 3 {
+					cell = string(runes[:opts.MaxColWidth-3]) + "..."
+				} else {
+					cell = string(runes[:opts.MaxColWidth])
+				}
+			}
+			records[i][j] = cell
+			if n := utf8.RuneCountInString(cell); n > maxChars[j] {
+				maxChars[j] = n
+			}
+		}
+	}
+
+	str := fmt.Sprintf("[%dx%d] DataFrame\n\n", nrows, ncols)
+	for i := range records {
+		row := records[i]
+		if n := utf8.RuneCountInString(row[0]); n < maxChars[0]+1 {
+			row[0] = strings.Repeat(" ", maxChars[0]+1-n) + row[0]
+		}
+		for j := 1; j < len(row)-1; j++ {
+			if n := utf8.RuneCountInString(row[j]); n < maxChars[j] {
+				row[j] += strings.Repeat(" ", maxChars[j]-n)
+			}
+		}
+		str += strings.Join(row, " ") + "\n"
+	}
+	if hiddenCols > 0 {
+		str += fmt.Sprintf("... (%d more cols)\n", hiddenCols)
+	}
+	return str
+}
+
+// HTMLOption is the type used to configure HTML
+type HTMLOption func(*htmlOptions)
+
+type htmlOptions struct {
+	// maxRows is the maximum number of rows rendered, zero meaning no limit.
+	maxRows int
+	// emptyNA renders NA elements as empty cells instead of "NaN".
+	emptyNA bool
+}
+
+// HTMLMaxRows sets the maxRows option for htmlOptions.
+func HTMLMaxRows(n int) HTMLOption {
+	return func(c *htmlOptions) {
+		c.maxRows = n
+	}
+}
+
+// HTMLEmptyNA sets the emptyNA option for htmlOptions.
+func HTMLEmptyNA(b bool) HTMLOption {
+	return func(c *htmlOptions) {
+		c.emptyNA = b
+	}
+}
+
+// HTML returns an HTML table representation of the DataFrame for front-ends
+// that render HTML, such as notebooks. The header holds the column names
+// followed by a row with their types, and every body row starts with its index.
+// Like String, only the first 10 rows are rendered unless HTMLMaxRows says
+// otherwise.
+func (df DataFrame) HTML(options ...HTMLOption) string {
+	cfg := htmlOptions{maxRows: 10}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if df.Err != nil {
+		return fmt.Sprintf("<p>DataFrame error: %s</p>", html.EscapeString(df.Err.Error()))
+	}
+
+	var b strings.Builder
+	b.WriteString("<table class=\"dataframe\">\n<thead>\n<tr><th></th>")
+	for _, col := range df.columns {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(col.Name))
+	}
+	b.WriteString("</tr>\n<tr><th></th>")
+	for _, col := range df.columns {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(fmt.Sprintf("<%v>", col.Type())))
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	nrows := df.nrows
+	if cfg.maxRows > 0 && nrows > cfg.maxRows {
+		nrows = cfg.maxRows
+	}
+	for i := 0; i < nrows; i++ {
+		fmt.Fprintf(&b, "<tr><th>%d</th>", i)
+		for _, col := range df.columns {
+			e := col.Elem(i)
+			cell := e.String()
+			if e.IsNA() && cfg.emptyNA {
+				cell = ""
+			}
+			fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+	if nrows < df.nrows {
+		b.WriteString("<tr><th>...</th>")
+		b.WriteString(strings.Repeat("<td>...</td>", df.ncols))
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return b.String()
+}
+
+// seq returns the integers 0 to n-1.
+func seq(n int) []int {
+	ret := make([]int, n)
+	for i := range ret {
+		ret[i] = i
+	}
+	return ret
+}
+
 // Returns error or nil if no error occured
 func (df *DataFrame) Error() error {
 	return df.Err
@@ -260,6 +448,7 @@ func (df DataFrame) print(
 // =======================================================
 
 // Set will update the values of a DataFrame for the rows selected via indexes.
+// The row index set by SetIndex, if any, is rebuilt to match the new values.
 func (df DataFrame) Set(indexes series.Indexes, newvalues DataFrame) DataFrame {
 	if df.Err != nil {
 		return df
@@ -278,6 +467,9 @@ func (df DataFrame) Set(indexes series.Indexes, newvalues DataFrame) DataFrame {
 			return df
 		}
 	}
+	if df.index != nil {
+		*df.index = *newRowIndex(df.index.column, df.columns[df.colIndex(df.index.column)])
+	}
 	return df
 }
 
@@ -387,48 +579,15 @@ func (df DataFrame) GroupBy(colnames ...string) *Groups {
 	if len(colnames) <= 0 {
 		return nil
 	}
-	groupDataFrame := make(map[string]DataFrame)
-	groupSeries := make(map[string][]map[string]interface{})
-	// Check that colname exist on dataframe
-	for _, c := range colnames {
-		if idx := findInStringSlice(c, df.Names()); idx == -1 {
-			return &Groups{Err: fmt.Errorf("GroupBy: can't find column name: %s", c)}
-		}
-	}
-
-	for _, s := range df.Maps() {
-		// Gen Key for per Series
-		key := ""
-		for i, c := range colnames {
-			format := ""
-			if i == 0 {
-				format = "%s%"
-			} else {
-				format = "%s_%"
-			}
-			switch s[c].(type) {
-			case string, bool:
-				format += "s"
-			case int, int16, int32, int64:
-				format += "d"
-			case float32, float64:
-				format += "f"
-			default:
-				return &Groups{Err: fmt.Errorf("GroupBy: type not found")}
-			}
-			key = fmt.Sprintf(format, key, s[c])
-		}
-		groupSeries[key] = append(groupSeries[key], s)
-	}
-
-	// Save column types
-	colTypes := map[string]series.Type{}
-	for _, c := range df.columns {
-		colTypes[c.Name] = c.Type()
+	// Rows are partitioned on the exact tuple of group values, while the
+	// group keys keep the readable "v1_v2" form; see groupRowIndexes.
+	keys, rows, err := df.groupRowIndexes(colnames...)
+	if err != nil {
+		return &Groups{Err: fmt.Errorf("GroupBy: %v", err)}
 	}
-
-	for k, cMaps := range groupSeries {
-		groupDataFrame[k] = LoadMaps(cMaps, WithTypes(colTypes))
+	groupDataFrame := make(map[string]DataFrame, len(keys))
+	for i, k := range keys {
+		groupDataFrame[k] = df.Subset(rows[i])
 	}
 	groups := &Groups{groups: groupDataFrame, colnames: colnames}
 	return groups
@@ -447,6 +606,11 @@ const (
 	Aggregation_SUM                               // SUM
 	Aggregation_COUNT                             // COUNT
 	Aggregation_CONCAT                            // CONCAT
+	Aggregation_PROD                              // PROD
+
+	// aggregationCount is one past the last AggregationType. New types go
+	// above it so loops over every type pick them up.
+	aggregationCount
 )
 
 // Groups : structure generated by groupby
@@ -455,9 +619,16 @@ type Groups struct {
 	colnames    []string
 	aggregation DataFrame
 	Err         error
+
+	// aggNaming names the aggregated columns, see WithAggNaming.
+	aggNaming func(col string, agg AggregationType) string
 }
 
 // Aggregation :Aggregate dataframe by aggregation type and aggregation column name
+//
+// The result holds the group columns followed by one `<col>_<AGG>` column per
+// requested aggregation, in the order they were given, with one row per group
+// sorted ascending by the group columns.
 func (gps Groups) Aggregation(typs []AggregationType, colnames []string) DataFrame {
 	if gps.groups == nil {
 		return DataFrame{Err: fmt.Errorf("Aggregation: input is nil")}
@@ -465,21 +636,37 @@ func (gps Groups) Aggregation(typs []AggregationType, colnames []string) DataFra
 	if len(typs) != len(colnames) {
 		return DataFrame{Err: fmt.Errorf("Aggregation: len(typs) != len(colnames)")}
 	}
-	dfMaps := make([]map[string]interface{}, 0)
-	for _, df := range gps.groups {
-		targetMap := df.Maps()[0]
-		curMap := make(map[string]interface{})
+	keys := make([]string, 0, len(gps.groups))
+	for k := range gps.groups {
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return DataFrame{Err: fmt.Errorf("Aggregation: no groups")}
+	}
+	sort.Strings(keys)
+
+	groupCells := make([][]interface{}, len(gps.colnames))
+	groupTypes := make([]series.Type, len(gps.colnames))
+	aggCells := make([][]interface{}, len(colnames))
+	aggTypes := make([]series.Type, len(colnames))
+	for k, key := range keys {
+		df := gps.groups[key]
 		// add columns of  group by
-		for _, c := range gps.colnames {
-			if value, ok := targetMap[c]; ok {
-				curMap[c] = value
-			} else {
+		for j, c := range gps.colnames {
+			idx := df.colIndex(c)
+			if idx == -1 {
 				return DataFrame{Err: fmt.Errorf("Aggregation: can't find column name: %s", c)}
 			}
+			groupTypes[j] = df.columns[idx].Type()
+			groupCells[j] = append(groupCells[j], df.columns[idx].Elem(0))
 		}
 		// Aggregation
 		for i, c := range colnames {
-			curSeries := df.Col(c)
+			idx := df.colIndex(c)
+			if idx == -1 {
+				return DataFrame{Err: fmt.Errorf("Aggregation: can't find column name: %s", c)}
+			}
+			curSeries := df.columns[idx]
 			var value interface{}
 			switch typs[i] {
 			case Aggregation_MAX:
@@ -494,6 +681,8 @@ func (gps Groups) Aggregation(typs []AggregationType, colnames []string) DataFra
 				value = curSeries.StdDev()
 			case Aggregation_SUM:
 				value = curSeries.Sum()
+			case Aggregation_PROD:
+				value = curSeries.Prod()
 			case Aggregation_COUNT:
 				value = float64(curSeries.Len())
 			case Aggregation_CONCAT:
@@ -502,29 +691,31 @@ func (gps Groups) Aggregation(typs []AggregationType, colnames []string) DataFra
 			default:
 				return DataFrame{Err: fmt.Errorf("Aggregation: this method %s not found", typs[i])}
 			}
-			curMap[fmt.Sprintf("%s_%s", c, typs[i])] = value
+			if k == 0 {
+				aggCells[i] = make([]interface{}, len(keys))
+				aggTypes[i] = series.Float
+				if typs[i] == Aggregation_CONCAT {
+					aggTypes[i] = series.String
+				}
+			}
+			aggCells[i][k] = value
 		}
-		dfMaps = append(dfMaps, curMap)
 	}
 
-	// Save column types
-	colTypes := map[string]series.Type{}
-	for k, v := range dfMaps[0] {
-		switch v.(type) {
-		case string:
-			colTypes[k] = series.String
-		case int, int16, int32, int64:
-			colTypes[k] = series.Int
-		case float32, float64:
-			colTypes[k] = series.Float
-		case bool:
-			colTypes[k] = series.Bool
-		default:
-			colTypes[k] = series.String // 默认使用字符串类型
+	columns := make([]series.Series, 0, len(gps.colnames)+len(colnames))
+	order := make([]Order, len(gps.colnames))
+	for j, c := range gps.colnames {
+		columns = append(columns, series.New(groupCells[j], groupTypes[j], c))
+		order[j] = Sort(c)
+	}
+	for i, c := range colnames {
+		name := fmt.Sprintf("%s_%s", c, typs[i])
+		if gps.aggNaming != nil {
+			name = gps.aggNaming(c, typs[i])
 		}
+		columns = append(columns, series.New(aggCells[i], aggTypes[i], name))
 	}
-
-	gps.aggregation = LoadMaps(dfMaps, WithTypes(colTypes))
+	gps.aggregation = New(columns...).Arrange(order...)
 	return gps.aggregation
 }
 
@@ -547,6 +738,31 @@ func (df DataFrame) Rename(newname, oldname string) DataFrame {
 
 	copy := df.Copy()
 	copy.columns[idx].Name = newname
+	if copy.index != nil && copy.index.column == oldname {
+		copy.index.column = newname
+	}
+	return copy
+}
+
+// RenameFunc changes the name of every column of a DataFrame to f(name). It
+// fails if two columns end up with the same name.
+func (df DataFrame) RenameFunc(f func(string) string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	copy := df.Copy()
+	seen := make(map[string]string, df.ncols)
+	for i, col := range copy.columns {
+		newname := f(col.Name)
+		if prev, ok := seen[newname]; ok {
+			return DataFrame{Err: fmt.Errorf("rename: columns %q and %q both renamed to %q", prev, col.Name, newname)}
+		}
+		seen[newname] = col.Name
+		copy.columns[i].Name = newname
+	}
+	if copy.index != nil {
+		copy.index.column = f(copy.index.column)
+	}
 	return copy
 }
 
@@ -562,8 +778,17 @@ func (df DataFrame) CBind(dfb DataFrame) DataFrame {
 	return New(cols...)
 }
 
+// Bind places the columns of dfb to the right of the columns of this
+// DataFrame. Both must have the same number of rows. Column names present on
+// both sides get the suffixes set with WithLeftSuffix and WithRightSuffix, as
+// with CrossJoin.
+func (df DataFrame) Bind(dfb DataFrame, opts ...nameSuffixOption) DataFrame {
+	return Bind([]DataFrame{df, dfb}, opts...)
+}
+
 // RBind matches the column names of two DataFrames and returns combined
-// rows from both of them.
+// rows from both of them. String columns take the rows of other types as
+// text, see concatColumns.
 func (df DataFrame) RBind(dfb DataFrame) DataFrame {
 	if df.Err != nil {
 		return df
@@ -580,7 +805,7 @@ func (df DataFrame) RBind(dfb DataFrame) DataFrame {
 
 		originalSeries := df.columns[k]
 		addedSeries := dfb.columns[idx]
-		newSeries := originalSeries.Concat(addedSeries)
+		newSeries := concatColumns(originalSeries, addedSeries)
 		if err := newSeries.Err; err != nil {
 			return DataFrame{Err: fmt.Errorf("rbind: %v", err)}
 		}
@@ -628,7 +853,7 @@ func (df DataFrame) Concat(dfb DataFrame) DataFrame {
 		} else {
 			b = series.New(make([]struct{}, dfb.nrows), a.Type(), a.Name)
 		}
-		newSeries := a.Concat(b)
+		newSeries := concatColumns(a, b)
 		if err := newSeries.Err; err != nil {
 			return DataFrame{Err: fmt.Errorf("concat: %v", err)}
 		}
@@ -637,14 +862,33 @@ func (df DataFrame) Concat(dfb DataFrame) DataFrame {
 	return New(expandedSeries...)
 }
 
+// concatColumns concatenates two columns with series.Series.Concat, except
+// that a String column takes the elements of b as text whatever its type,
+// as RBind and Concat have always done. Appending String elements to a
+// numeric column is still an error.
+func concatColumns(a, b series.Series) series.Series {
+	if a.Type() == series.String && b.Type() != series.String && b.Err == nil {
+		b = series.New(b, series.String, b.Name)
+	}
+	return a.Concat(b)
+}
+
 // Mutate changes a column of the DataFrame with the given Series or adds it as
-// a new column if the column name does not exist.
+// a new column if the column name does not exist. The Series must have one
+// element per row, unless the DataFrame has no columns yet, in which case it
+// takes the length of the Series.
 func (df DataFrame) Mutate(s series.Series) DataFrame {
 	if df.Err != nil {
 		return df
 	}
+	if s.Err != nil {
+		return DataFrame{Err: fmt.Errorf("mutate: argument has errors: %v", s.Err)}
+	}
+	if df.ncols == 0 {
+		return New(s)
+	}
 	if s.Len() != df.nrows {
-		return DataFrame{Err: fmt.Errorf("mutate: wrong dimensions")}
+		return DataFrame{Err: fmt.Errorf("mutate: wrong dimensions: series %q has %d elements but the dataframe has %d rows", s.Name, s.Len(), df.nrows)}
 	}
 	df = df.Copy()
 	// Check that colname exist on dataframe
@@ -671,6 +915,66 @@ func (df DataFrame) Mutate(s series.Series) DataFrame {
 	return df
 }
 
+// Coalesce adds a column newCol holding, for every row, the first non-NA value
+// found among cols, scanned from left to right. Rows where all of them are NA
+// stay NA. The new column takes the widest type of the source columns, in the
+// order String -> Float -> Int -> Bool.
+func (df DataFrame) Coalesce(newCol string, cols ...string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if len(cols) == 0 {
+		return DataFrame{Err: fmt.Errorf("coalesce: no columns given")}
+	}
+	sources := make([]series.Series, len(cols))
+	types := make([]series.Type, len(cols))
+	for i, colname := range cols {
+		idx := df.colIndex(colname)
+		if idx == -1 {
+			return DataFrame{Err: fmt.Errorf("coalesce: colname %s doesn't exist", colname)}
+		}
+		sources[i] = df.columns[idx]
+		types[i] = sources[i].Type()
+	}
+	t := commonType(types...)
+
+	values := make([]interface{}, df.nrows)
+	for i := 0; i < df.nrows; i++ {
+		for _, s := range sources {
+			if e := s.Elem(i); !e.IsNA() {
+				values[i] = e
+				break
+			}
+		}
+	}
+	return df.Mutate(series.New(values, t, newCol))
+}
+
+// commonType returns the widest of the given types, in the order
+// String -> Float -> Int -> Bool.
+func commonType(types ...series.Type) series.Type {
+	var hasStrings, hasFloats, hasInts bool
+	for _, t := range types {
+		switch t {
+		case series.String:
+			hasStrings = true
+		case series.Float:
+			hasFloats = true
+		case series.Int:
+			hasInts = true
+		}
+	}
+	switch {
+	case hasStrings:
+		return series.String
+	case hasFloats:
+		return series.Float
+	case hasInts:
+		return series.Int
+	}
+	return series.Bool
+}
+
 // Row returns a map[string]interface{} representing the row at the given index
 func (df DataFrame) Row(index int) (map[string]series.Element, map[string]interface{}) {
 	if df.Err != nil {
@@ -758,15 +1062,11 @@ func (df DataFrame) FilterAggregation(agg Aggregation, filters ...F) DataFrame {
 		return df.Copy()
 	}
 
-	res, err := compResults[0].Bool()
-	if err != nil {
-		return DataFrame{Err: fmt.Errorf("filter: %v", err)}
-	}
+	// Unknown (NA) comparison results never select a row, which under either
+	// aggregation gives the same rows as three-valued logic.
+	res := maskValues(compResults[0])
 	for i := 1; i < len(compResults); i++ {
-		nextRes, err := compResults[i].Bool()
-		if err != nil {
-			return DataFrame{Err: fmt.Errorf("filter: %v", err)}
-		}
+		nextRes := maskValues(compResults[i])
 		for j := 0; j < len(res); j++ {
 			switch agg {
 			case Or:
@@ -781,6 +1081,17 @@ func (df DataFrame) FilterAggregation(agg Aggregation, filters ...F) DataFrame {
 	return df.Subset(res)
 }
 
+// maskValues returns the values of a Bool mask with NA entries as false.
+func maskValues(mask series.Series) []bool {
+	ret := make([]bool, mask.Len())
+	for i := range ret {
+		if e := mask.Elem(i); !e.IsNA() {
+			ret[i], _ = e.Bool()
+		}
+	}
+	return ret
+}
+
 // Order is the ordering structure
 type Order struct {
 	Colname string
@@ -840,6 +1151,24 @@ func (df DataFrame) Arrange(order ...Order) DataFrame {
 	return df.Subset(origIdx)
 }
 
+// SortValues sorts the rows of a DataFrame by the given columns, with reverse[i]
+// controlling the direction of columns[i]. Each key is compared according to
+// its column type (numerically for Int and Float, lexically for String) and the
+// sort is stable, so rows that are equal on every key keep their input order.
+func (df DataFrame) SortValues(columns []string, reverse []bool) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if len(columns) != len(reverse) {
+		return DataFrame{Err: fmt.Errorf("sort values: got %d columns but %d reverse flags", len(columns), len(reverse))}
+	}
+	order := make([]Order, len(columns))
+	for i, colname := range columns {
+		order[i] = Order{Colname: colname, Reverse: reverse[i]}
+	}
+	return df.Arrange(order...)
+}
+
 // Capply applies the given function to the columns of a DataFrame
 func (df DataFrame) Capply(f func(series.Series) series.Series) DataFrame {
 	if df.Err != nil {
@@ -854,6 +1183,62 @@ func (df DataFrame) Capply(f func(series.Series) series.Series) DataFrame {
 	return New(columns...)
 }
 
+// ApplyMapOption is the type used to configure ApplyMap
+type ApplyMapOption func(*applyMapOptions)
+
+type applyMapOptions struct {
+	// inferTypes detects the type of every resulting column from its values
+	// instead of keeping the original column type.
+	inferTypes bool
+}
+
+// ApplyMapInferTypes sets the inferTypes option for applyMapOptions.
+func ApplyMapInferTypes(b bool) ApplyMapOption {
+	return func(c *applyMapOptions) {
+		c.inferTypes = b
+	}
+}
+
+// ApplyMap applies the given function to every cell of a DataFrame, passing
+// the name of the column the element belongs to. Returning nil yields an NA
+// element. By default the values are converted back to the type of their
+// column; with ApplyMapInferTypes each column type is detected again from the
+// returned values.
+func (df DataFrame) ApplyMap(f func(col string, e series.Element) interface{}, options ...ApplyMapOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := applyMapOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	columns := make([]series.Series, df.ncols)
+	for j, s := range df.columns {
+		values := make([]interface{}, df.nrows)
+		for i := range values {
+			values[i] = f(s.Name, s.Elem(i))
+		}
+		if !cfg.inferTypes {
+			columns[j] = series.New(values, s.Type(), s.Name)
+			continue
+		}
+		records := make([]string, df.nrows)
+		for i, v := range values {
+			records[i] = "NaN"
+			if v != nil {
+				records[i] = fmt.Sprint(v)
+			}
+		}
+		t, err := findType(records)
+		if err != nil {
+			t = s.Type()
+		}
+		columns[j] = series.New(records, t, s.Name)
+	}
+	return New(columns...)
+}
+
 // Rapply applies the given function to the rows of a DataFrame. Prior to applying
 // the function the elements of each row are cast to a Series of a specific
 // type. In order of priority: String -> Float -> Int -> Bool. This casting also
@@ -979,6 +1364,24 @@ func (df DataFrame) Equal(other DataFrame) bool {
 	return true
 }
 
+// EqualApprox compares two DataFrames like Equal but lets the elements of
+// Float columns differ by up to tol, and considers elements that are NA on both
+// sides equal, as series.Series.EqualApprox does.
+func (df DataFrame) EqualApprox(other DataFrame, tol float64) bool {
+	if df.Err != nil || other.Err != nil {
+		return false
+	}
+	if df.nrows != other.nrows || df.ncols != other.ncols {
+		return false
+	}
+	for i, col := range df.columns {
+		if !col.EqualApprox(other.columns[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
 // Read/Write Methods
 // =================
 
@@ -1014,6 +1417,9 @@ type loadOptions struct {
 
 	// The types of specific columns can be specified via column name.
 	types map[string]series.Type
+
+	// The format of localized numbers, if any.
+	numberFormat *series.NumberFormat
 }
 
 // DefaultType sets the defaultType option for loadOptions.
@@ -1079,6 +1485,17 @@ func WithComments(b rune) LoadOption {
 	}
 }
 
+// WithNumberFormat sets the format of the numbers in the loaded values, such
+// as series.EUNumberFormat for "1.234,56". Only columns that end up Int or
+// Float are parsed with it; the others keep their raw values. Columns already
+// loaded can be converted with series.Series.Astype and the
+// series.ParseNumberFormat option.
+func WithNumberFormat(f series.NumberFormat) LoadOption {
+	return func(c *loadOptions) {
+		c.numberFormat = &f
+	}
+}
+
 // LoadStructs creates a new DataFrame from arbitrary struct slices.
 //
 // LoadStructs will ignore unexported fields inside an struct. Note also that
@@ -1280,6 +1697,13 @@ func LoadRecords(records [][]string, options ...LoadOption) DataFrame {
 		}
 		rawcols[i] = rawcol
 
+		var normalized []string
+		if cfg.numberFormat != nil {
+			normalized = make([]string, len(rawcol))
+			for j, str := range rawcol {
+				normalized[j] = cfg.numberFormat.Normalize(str)
+			}
+		}
 		t, ok := cfg.types[colname]
 		if !ok {
 			t = cfg.defaultType
@@ -1287,8 +1711,16 @@ func LoadRecords(records [][]string, options ...LoadOption) DataFrame {
 				if l, err := findType(rawcol); err == nil {
 					t = l
 				}
+				if normalized != nil && t == series.String {
+					if l, err := findType(normalized); err == nil && (l == series.Int || l == series.Float) {
+						t = l
+					}
+				}
 			}
 		}
+		if normalized != nil && (t == series.Int || t == series.Float) {
+			rawcols[i] = normalized
+		}
 		types[i] = t
 	}
 
@@ -1318,6 +1750,22 @@ func LoadRecords(records [][]string, options ...LoadOption) DataFrame {
 	return df
 }
 
+// LoadRecordsWithTypes creates a new DataFrame from records like LoadRecords,
+// forcing the columns named in types to the given type regardless of their
+// content. Cells that can't be coerced to the forced type become NA. The first
+// record is the header naming the columns, and every key of types must match
+// one of them.
+func LoadRecordsWithTypes(records [][]string, types map[string]series.Type, options ...LoadOption) DataFrame {
+	if len(records) > 0 {
+		for colname := range types {
+			if findInStringSlice(colname, records[0]) == -1 {
+				return DataFrame{Err: fmt.Errorf("load records: can't find column %q for the given types", colname)}
+			}
+		}
+	}
+	return LoadRecords(records, append(options, HasHeader(true), WithTypes(types))...)
+}
+
 // LoadMaps creates a new DataFrame based on the given maps. This function assumes
 // that every map on the array represents a row of observations.
 func LoadMaps(maps []map[string]interface{}, options ...LoadOption) DataFrame {
@@ -1412,43 +1860,138 @@ func ReadCSV(r io.Reader, options ...LoadOption) DataFrame {
 	return LoadRecords(records, options...)
 }
 
-// ReadJSON reads a JSON array from a io.Reader and builds a DataFrame with the
-// resulting records.
-func ReadJSON(r io.Reader, options ...LoadOption) DataFrame {
-	var m []map[string]interface{}
-	d := json.NewDecoder(r)
-	d.UseNumber()
-	err := d.Decode(&m)
-	if err != nil {
-		return DataFrame{Err: err}
+// ReadCSVChunked reads a CSV file from a io.Reader in chunks of up to chunkRows
+// records, calling f with a DataFrame for each of them in order. The schema is
+// taken from the first chunk and applied to every following one, so the column
+// names and types stay stable across chunks. Reading stops at the first error,
+// either from the reader or returned by f.
+func ReadCSVChunked(r io.Reader, chunkRows int, f func(DataFrame) error, options ...LoadOption) error {
+	if chunkRows <= 0 {
+		return fmt.Errorf("read csv chunked: chunkRows must be positive, got %d", chunkRows)
 	}
-	return LoadMaps(m, options...)
-}
-
-// WriteOption is the type used to configure the writing of elements
-type WriteOption func(*writeOptions)
-
-type writeOptions struct {
-	// Specifies whether the header is also written
-	writeHeader bool
-}
-
-// WriteHeader sets the writeHeader option for writeOptions.
-func WriteHeader(b bool) WriteOption {
-	return func(c *writeOptions) {
-		c.writeHeader = b
+	csvReader := csv.NewReader(r)
+	cfg := loadOptions{
+		delimiter:  ',',
+		lazyQuotes: false,
+		comment:    0,
+		hasHeader:  true,
 	}
-}
-
-// WriteCSV writes the DataFrame to the given io.Writer as a CSV file.
-func (df DataFrame) WriteCSV(w io.Writer, options ...WriteOption) error {
-	if df.Err != nil {
-		return df.Err
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	csvReader.Comma = cfg.delimiter
+	csvReader.LazyQuotes = cfg.lazyQuotes
+	csvReader.Comment = cfg.comment
+
+	var header []string
+	if cfg.hasHeader {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		header = record
+	}
+
+	var schema []LoadOption
+	flush := func(records [][]string) error {
+		var df DataFrame
+		if schema == nil {
+			if header != nil {
+				records = append([][]string{header}, records...)
+			}
+			df = LoadRecords(records, options...)
+			if df.Err != nil {
+				return df.Err
+			}
+			types := make(map[string]series.Type, df.ncols)
+			for _, col := range df.columns {
+				types[col.Name] = col.Type()
+			}
+			schema = append(append([]LoadOption{}, options...), HasHeader(false), Names(df.Names()...), WithTypes(types))
+		} else {
+			df = LoadRecords(records, schema...)
+			if df.Err != nil {
+				return df.Err
+			}
+		}
+		return f(df)
+	}
+
+	chunk := make([][]string, 0, chunkRows)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		chunk = append(chunk, record)
+		if len(chunk) == chunkRows {
+			if err := flush(chunk); err != nil {
+				return err
+			}
+			chunk = make([][]string, 0, chunkRows)
+		}
+	}
+	if len(chunk) > 0 {
+		return flush(chunk)
+	}
+	return nil
+}
+
+// ReadJSON reads a JSON array from a io.Reader and builds a DataFrame with the
+// resulting records.
+func ReadJSON(r io.Reader, options ...LoadOption) DataFrame {
+	var m []map[string]interface{}
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	err := d.Decode(&m)
+	if err != nil {
+		return DataFrame{Err: err}
+	}
+	return LoadMaps(m, options...)
+}
+
+// WriteOption is the type used to configure the writing of elements
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	// Specifies whether the header is also written
+	writeHeader bool
+
+	// The token written for NA elements
+	naValue string
+}
+
+// WriteHeader sets the writeHeader option for writeOptions.
+func WriteHeader(b bool) WriteOption {
+	return func(c *writeOptions) {
+		c.writeHeader = b
+	}
+}
+
+// WriteNAValue sets the naValue option for writeOptions.
+func WriteNAValue(na string) WriteOption {
+	return func(c *writeOptions) {
+		c.naValue = na
+	}
+}
+
+// WriteCSV writes the DataFrame to the given io.Writer as a CSV file.
+func (df DataFrame) WriteCSV(w io.Writer, options ...WriteOption) error {
+	if df.Err != nil {
+		return df.Err
 	}
 
 	// Set the default write options
 	cfg := writeOptions{
 		writeHeader: true,
+		naValue:     "NaN",
 	}
 
 	// Set any custom write options
@@ -1457,6 +2000,15 @@ func (df DataFrame) WriteCSV(w io.Writer, options ...WriteOption) error {
 	}
 
 	records := df.Records()
+	if cfg.naValue != "NaN" {
+		for j, col := range df.columns {
+			for i := 0; i < df.nrows; i++ {
+				if col.Elem(i).IsNA() {
+					records[i+1][j] = cfg.naValue
+				}
+			}
+		}
+	}
 	if !cfg.writeHeader {
 		records = records[1:]
 	}
@@ -1464,6 +2016,16 @@ func (df DataFrame) WriteCSV(w io.Writer, options ...WriteOption) error {
 	return csv.NewWriter(w).WriteAll(records)
 }
 
+// ToCSVString returns the DataFrame rendered as CSV, configured like WriteCSV.
+// It returns the empty string if the DataFrame has errors.
+func (df DataFrame) ToCSVString(options ...WriteOption) string {
+	var buf bytes.Buffer
+	if err := df.WriteCSV(&buf, options...); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
 // WriteJSON writes the DataFrame to the given io.Writer as a JSON array.
 func (df DataFrame) WriteJSON(w io.Writer) error {
 	if df.Err != nil {
@@ -1661,6 +2223,108 @@ func (df DataFrame) Col(colname string) series.Series {
 	return df.columns[idx].Copy()
 }
 
+// JoinOption is the type used to configure JoinWithOptions
+type JoinOption func(*joinOptions)
+
+type joinOptions struct {
+	// validate asserts the cardinality of the join keys: "1:1" (keys unique
+	// on both sides), "1:m" (unique on the left side), "m:1" (unique on the
+	// right side) or "m:m" (no check).
+	validate string
+}
+
+// JoinValidate sets the validate option for joinOptions.
+func JoinValidate(validate string) JoinOption {
+	return func(o *joinOptions) {
+		o.validate = validate
+	}
+}
+
+// JoinWithOptions joins two DataFrames on the given key columns. how is one of
+// "inner", "left", "right" or "outer", with the semantics of InnerJoin,
+// LeftJoin, RightJoin and OuterJoin. With JoinValidate the cardinality of the
+// keys is checked first, and a violation sets Err on the result naming the
+// first duplicated key instead of joining.
+func (df DataFrame) JoinWithOptions(b DataFrame, how string, keys []string, options ...JoinOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if b.Err != nil {
+		return b
+	}
+	cfg := joinOptions{validate: "m:m"}
+	for _, option := range options {
+		option(&cfg)
+	}
+	join, err := joinByName(how)
+	if err != nil {
+		return DataFrame{Err: err}
+	}
+	if err := df.validateJoin(b, cfg.validate, keys...); err != nil {
+		return DataFrame{Err: err}
+	}
+	return join(df, b, keys...)
+}
+
+// joinByName returns the join method matching how.
+func joinByName(how string) (func(DataFrame, DataFrame, ...string) DataFrame, error) {
+	switch how {
+	case "inner":
+		return DataFrame.InnerJoin, nil
+	case "left":
+		return DataFrame.LeftJoin, nil
+	case "right":
+		return DataFrame.RightJoin, nil
+	case "outer":
+		return DataFrame.OuterJoin, nil
+	default:
+		return nil, fmt.Errorf("join: unknown join %q", how)
+	}
+}
+
+// validateJoin checks the cardinality of the join keys between df and b, as
+// described on JoinValidate.
+func (df DataFrame) validateJoin(b DataFrame, validate string, keys ...string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("join keys not specified")
+	}
+	var checkLeft, checkRight bool
+	switch validate {
+	case "1:1":
+		checkLeft, checkRight = true, true
+	case "1:m":
+		checkLeft = true
+	case "m:1":
+		checkRight = true
+	case "m:m":
+	default:
+		return fmt.Errorf("join validation: unknown validate value %q", validate)
+	}
+	unique := func(d DataFrame, side string) error {
+		groupKeys, groups, err := d.groupRowIndexes(keys...)
+		if err != nil {
+			return fmt.Errorf("join validation: %v on %s DataFrame", err, side)
+		}
+		for k, idx := range groups {
+			if len(idx) > 1 {
+				return fmt.Errorf("join validation %q failed: key %q is duplicated on %s DataFrame", validate, groupKeys[k], side)
+			}
+		}
+		return nil
+	}
+	if checkLeft {
+		if err := unique(df, "left"); err != nil {
+			return err
+		}
+	}
+	if checkRight {
+		if err := unique(b, "right"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // InnerJoin returns a DataFrame containing the inner join of two DataFrames.
 func (df DataFrame) InnerJoin(b DataFrame, keys ...string) DataFrame {
 	if len(keys) == 0 {
@@ -2080,6 +2744,79 @@ type nameSuffinx struct {
 	right string
 }
 
+// JoinKey is a join key derived from a column. Transform maps every non-NA
+// element of Column to the value compared across the DataFrames; a nil
+// Transform compares the elements as strings. NA elements never match.
+type JoinKey struct {
+	Column    string
+	Transform func(series.Element) string
+}
+
+// JoinOn joins two DataFrames on derived keys without materializing them as
+// columns first. how is one of "inner", "left", "right" or "outer", with the
+// semantics of InnerJoin, LeftJoin, RightJoin and OuterJoin. The result holds
+// the columns of df followed by those of b, key columns included, with
+// duplicated names made unique as in New.
+func (df DataFrame) JoinOn(b DataFrame, how string, keys ...JoinKey) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if b.Err != nil {
+		return b
+	}
+	if len(keys) == 0 {
+		return DataFrame{Err: fmt.Errorf("join on: join keys not specified")}
+	}
+	join, err := joinByName(how)
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("join on: %v", err)}
+	}
+
+	names := make([]string, len(keys))
+	for k := range keys {
+		names[k] = fmt.Sprintf("__key%d", k)
+		for df.colIndex(names[k]) >= 0 || b.colIndex(names[k]) >= 0 {
+			names[k] = "_" + names[k]
+		}
+	}
+	derive := func(d DataFrame, side string) DataFrame {
+		derived := make([]series.Series, 0, len(keys)+d.ncols)
+		for k, key := range keys {
+			idx := d.colIndex(key.Column)
+			if idx < 0 {
+				return DataFrame{Err: fmt.Errorf("join on: can't find key %q on %s DataFrame", key.Column, side)}
+			}
+			values := make([]interface{}, d.nrows)
+			for i := range values {
+				e := d.columns[idx].Elem(i)
+				switch {
+				case e.IsNA():
+				case key.Transform != nil:
+					values[i] = key.Transform(e)
+				default:
+					values[i] = e.String()
+				}
+			}
+			derived = append(derived, series.New(values, series.String, names[k]))
+		}
+		derived = append(derived, d.columns...)
+		return New(derived...)
+	}
+	left, right := derive(df, "left"), derive(b, "right")
+	if left.Err != nil {
+		return left
+	}
+	if right.Err != nil {
+		return right
+	}
+
+	joined := join(left, right, names...)
+	if joined.Err != nil {
+		return DataFrame{Err: fmt.Errorf("join on: %v", joined.Err)}
+	}
+	return New(joined.columns[len(keys):]...)
+}
+
 type nameSuffixOption func(*nameSuffinx)
 
 func WithLeftSuffix(suffix string) nameSuffixOption {
@@ -2474,6 +3211,62 @@ func (df DataFrame) Describe() DataFrame {
 	return ddf
 }
 
+// DescribeCategorical prints the summary statistics for each String and Bool
+// column of the dataframe: the number of valid elements, the number of
+// distinct values, the most frequent value and its frequency. Ties on the
+// frequency go to the value appearing first, and NA elements are ignored.
+func (df DataFrame) DescribeCategorical() DataFrame {
+	labels := series.Strings([]string{
+		"count",
+		"unique",
+		"top",
+		"freq",
+	})
+	labels.Name = "column"
+
+	ss := []series.Series{labels}
+
+	for _, col := range df.columns {
+		if col.Type() != series.String && col.Type() != series.Bool {
+			continue
+		}
+		counts := make(map[string]int)
+		var order []string
+		count := 0
+		for i := 0; i < col.Len(); i++ {
+			e := col.Elem(i)
+			if e.IsNA() {
+				continue
+			}
+			count++
+			v := e.String()
+			if counts[v] == 0 {
+				order = append(order, v)
+			}
+			counts[v]++
+		}
+		var top interface{}
+		freq := 0
+		for _, v := range order {
+			if counts[v] > freq {
+				top, freq = v, counts[v]
+			}
+		}
+		ss = append(ss, series.New([]interface{}{
+			strconv.Itoa(count),
+			strconv.Itoa(len(counts)),
+			top,
+			strconv.Itoa(freq),
+		},
+			series.String,
+			col.Name,
+		))
+	}
+
+	ddf := New(ss...)
+	return ddf
+}
+
 // ValuesOptions represents options for the ValuesIterator
 type ValuesOptions struct {
 	returnRowIndex  bool
@@ -2562,6 +3355,20 @@ func (df DataFrame) RowsIterator(opts ...ValuesOption) func() (int, map[string]i
 	}
 }
 
+// EachRow calls f for every row of the DataFrame in order, stopping as soon as
+// f returns false. It accepts the same options as RowsIterator.
+func (df DataFrame) EachRow(f func(i int, row map[string]interface{}) bool, opts ...ValuesOption) {
+	if df.Err != nil {
+		return
+	}
+	next := df.RowsIterator(opts...)
+	for i, row, ok := next(); ok; i, row, ok = next() {
+		if !f(i, row) {
+			return
+		}
+	}
+}
+
 // 运算类型
 type OperatorType int
 
@@ -2592,6 +3399,8 @@ func isValidType(op OperatorType, colType series.Type) bool {
 	}
 }
 
+// MinInColumns returns the row-wise minimum of the given numeric columns. NA
+// cells are skipped and a row that is NA in every selected column yields NA.
 func MinInColumns(df DataFrame, name string, columns ...string) series.Series {
 	// return minSeries
 	s := operator(df, OperatorMin, columns...)
@@ -2601,6 +3410,8 @@ func MinInColumns(df DataFrame, name string, columns ...string) series.Series {
 	return s
 }
 
+// MaxInColumns returns the row-wise maximum of the given numeric columns. NA
+// cells are skipped and a row that is NA in every selected column yields NA.
 func MaxInColumns(df DataFrame, name string, columns ...string) series.Series {
 	s := operator(df, OperatorMax, columns...)
 	if s.Name != "" {
@@ -2609,6 +3420,56 @@ func MaxInColumns(df DataFrame, name string, columns ...string) series.Series {
 	return s
 }
 
+// ColSums returns the sum of every numeric (Int or Float) column of the
+// DataFrame, keyed by column name. Other columns are skipped.
+func (df DataFrame) ColSums() map[string]float64 {
+	return df.reduceColumns(series.Series.Sum)
+}
+
+// ColMeans returns the mean of every numeric (Int or Float) column of the
+// DataFrame, keyed by column name. Other columns are skipped.
+func (df DataFrame) ColMeans() map[string]float64 {
+	return df.reduceColumns(series.Series.Mean)
+}
+
+// ColMins returns the minimum of every numeric (Int or Float) column of the
+// DataFrame, keyed by column name. Other columns are skipped.
+func (df DataFrame) ColMins() map[string]float64 {
+	return df.reduceColumns(series.Series.Min)
+}
+
+// ColMaxs returns the maximum of every numeric (Int or Float) column of the
+// DataFrame, keyed by column name. Other columns are skipped.
+func (df DataFrame) ColMaxs() map[string]float64 {
+	return df.reduceColumns(series.Series.Max)
+}
+
+// Reduce applies a custom reduction, such as the coefficient of variation, to
+// every column of the DataFrame and returns the results keyed by column name.
+// Non-numeric columns are not passed to f and yield NaN.
+func (df DataFrame) Reduce(f func(series.Series) float64) map[string]float64 {
+	ret := df.reduceColumns(f)
+	for _, col := range df.columns {
+		if _, ok := ret[col.Name]; !ok && df.Err == nil {
+			ret[col.Name] = math.NaN()
+		}
+	}
+	return ret
+}
+
+func (df DataFrame) reduceColumns(f func(series.Series) float64) map[string]float64 {
+	ret := make(map[string]float64)
+	if df.Err != nil {
+		return ret
+	}
+	for _, col := range df.columns {
+		if t := col.Type(); t == series.Int || t == series.Float {
+			ret[col.Name] = f(col)
+		}
+	}
+	return ret
+}
+
 func operator(df DataFrame, op OperatorType, columns ...string) series.Series {
 	type colInfo struct {
 		Name     string
@@ -2617,7 +3478,7 @@ func operator(df DataFrame, op OperatorType, columns ...string) series.Series {
 	}
 
 	var colInfos []colInfo
-	for index, colName := range columns {
+	for _, colName := range columns {
 		colIndex := df.colIndex(colName)
 		if colIndex != -1 && isValidType(op, df.columns[colIndex].Type()) {
 			info := colInfo{
@@ -2626,7 +3487,7 @@ func operator(df DataFrame, op OperatorType, columns ...string) series.Series {
 				Type:     df.columns[colIndex].Type(),
 			}
 
-			if index == 0 {
+			if len(colInfos) == 0 {
 				colInfos = append(colInfos, info)
 			} else {
 				if df.columns[colIndex].Type() == colInfos[0].Type {
@@ -2646,8 +3507,18 @@ func operator(df DataFrame, op OperatorType, columns ...string) series.Series {
 		return minSeries
 	}
 
+	// NA cells are skipped, so a row only stays NA when every selected column
+	// is NA on it.
 	for i := 0; i < minSeries.Len(); i++ {
 		for _, colInfo := range colInfos {
+			cur := df.columns[colInfo.ColIndex].Elem(i)
+			if cur.IsNA() {
+				continue
+			}
+			if minSeries.Elem(i).IsNA() {
+				minSeries.Elem(i).Set(cur)
+				continue
+			}
 			if op == OperatorMin && minSeries.Elem(i).Greater(df.columns[colInfo.ColIndex].Elem(i)) {
 				minSeries.Elem(i).Set(df.columns[colInfo.ColIndex].Elem(i))
 			}
@@ -2692,6 +3563,37 @@ func (df DataFrame) Distinct() DataFrame {
 	return df.Subset(uniqueIndices)
 }
 
+// DuplicatedMask returns a Bool Series marking the rows that repeat the values
+// of another row on the given columns, or on every column if none is given.
+// With keep "first" the first occurrence of every repeated row is taken as the
+// original and left unmarked, and with "last" the last one is.
+func (df DataFrame) DuplicatedMask(keep string, subset ...string) series.Series {
+	if df.Err != nil {
+		return series.Series{Err: df.Err}
+	}
+	if keep != "first" && keep != "last" {
+		return series.Series{Err: fmt.Errorf("duplicated mask: unknown keep %q", keep)}
+	}
+	if len(subset) == 0 {
+		subset = df.Names()
+	}
+	_, groups, err := df.groupRowIndexes(subset...)
+	if err != nil {
+		return series.Series{Err: fmt.Errorf("duplicated mask: %v", err)}
+	}
+	mask := make([]bool, df.nrows)
+	for _, idx := range groups {
+		original := idx[0]
+		if keep == "last" {
+			original = idx[len(idx)-1]
+		}
+		for _, i := range idx {
+			mask[i] = i != original
+		}
+	}
+	return series.New(mask, series.Bool, "duplicated")
+}
+
 func AntiJoin(df1, df2 DataFrame, on string) DataFrame {
 	// 检查输入
 	if df1.Err != nil {
@@ -2733,55 +3635,340 @@ func AntiJoin(df1, df2 DataFrame, on string) DataFrame {
 	return result
 }
 
-func Concat(dfs ...DataFrame) DataFrame {
-	if len(dfs) == 0 {
-		return New()
+// AsOfJoin matches every row of left with the nearest row of right by the on
+// column, in the given direction: "backward" picks the last right row whose key
+// is less than or equal, "forward" the first one whose key is greater than or
+// equal, and "nearest" the closest of both, preferring the backward one on ties
+// (numeric keys only). The result holds the left columns followed by the other
+// right columns, NA on left rows without a match. right must be sorted
+// ascending on on.
+func AsOfJoin(left, right DataFrame, on string, direction string) DataFrame {
+	if left.Err != nil {
+		return left
+	}
+	if right.Err != nil {
+		return DataFrame{Err: fmt.Errorf("as of join: right dataframe has errors: %v", right.Err)}
+	}
+	li, ri := left.colIndex(on), right.colIndex(on)
+	if li == -1 || ri == -1 {
+		return DataFrame{Err: fmt.Errorf("as of join: column '%s' not found in both dataframes", on)}
+	}
+	leftKeys, rightKeys := left.columns[li], right.columns[ri]
+	switch direction {
+	case "backward", "forward":
+	case "nearest":
+		if t := rightKeys.Type(); t != series.Int && t != series.Float {
+			return DataFrame{Err: fmt.Errorf("as of join: nearest direction needs numeric keys, got %s", t)}
+		}
+	default:
+		return DataFrame{Err: fmt.Errorf("as of join: unknown direction %q", direction)}
 	}
 
-	first := dfs[0]
-	for _, df := range dfs[1:] {
-		first = first.Concat(df)
+	after, err := rightKeys.SearchSorted(leftKeys, "right", series.WithCheckSorted(true))
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("as of join: right %v", err)}
+	}
+	before, err := rightKeys.SearchSorted(leftKeys, "left")
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("as of join: %v", err)}
+	}
+	valid := func(j int) bool {
+		return j >= 0 && j < right.nrows && !rightKeys.Elem(j).IsNA()
+	}
+	matches := make([]int, left.nrows)
+	for i := range matches {
+		matches[i] = -1
+		if leftKeys.Elem(i).IsNA() {
+			continue
+		}
+		back, fwd := after[i]-1, before[i]
+		switch direction {
+		case "backward":
+			if valid(back) {
+				matches[i] = back
+			}
+		case "forward":
+			if valid(fwd) {
+				matches[i] = fwd
+			}
+		case "nearest":
+			key := leftKeys.Elem(i).Float()
+			switch {
+			case valid(back) && valid(fwd):
+				matches[i] = back
+				if rightKeys.Elem(fwd).Float()-key < key-rightKeys.Elem(back).Float() {
+					matches[i] = fwd
+				}
+			case valid(back):
+				matches[i] = back
+			case valid(fwd):
+				matches[i] = fwd
+			}
+		}
 	}
 
-	return first
-}
-
-func contains[T int | float64 | string](s []T, str T) bool {
-	for _, v := range s {
-		if v == str {
-			return true
+	columns := make([]series.Series, 0, left.ncols+right.ncols-1)
+	columns = append(columns, left.columns...)
+	for j, col := range right.columns {
+		if j == ri {
+			continue
+		}
+		cells := make([]interface{}, left.nrows)
+		for i, m := range matches {
+			if m != -1 {
+				if e := col.Elem(m); !e.IsNA() {
+					cells[i] = e
+				}
+			}
 		}
+		columns = append(columns, series.New(cells, col.Type(), col.Name))
 	}
-	return false
+	return New(columns...)
 }
 
-type GroupOption func(DataFrame) DataFrame
+// Concat concatenates the rows of the given DataFrames, in order, as with
+// DataFrame.Concat. If any of them fails, the error names the offending input.
+func Concat(dfs ...DataFrame) DataFrame {
+	return ConcatWithOptions(dfs)
+}
 
-func WithLeftJoin(df DataFrame, keys ...string) GroupOption {
-	return func(other DataFrame) DataFrame {
-		return df.LeftJoin(other, keys...)
-	}
+// ConcatOption is the type used to configure ConcatWithOptions
+type ConcatOption func(*concatOptions)
+
+type concatOptions struct {
+	// sortColumns orders the resulting columns by name.
+	sortColumns bool
+	// resetErr skips the inputs holding an error instead of failing.
+	resetErr bool
 }
 
-func WithRightJoin(df DataFrame, keys ...string) GroupOption {
-	return func(other DataFrame) DataFrame {
-		return df.RightJoin(other, keys...)
+// WithSortColumns sets the sortColumns option for concatOptions.
+func WithSortColumns(b bool) ConcatOption {
+	return func(c *concatOptions) {
+		c.sortColumns = b
 	}
 }
 
-func WithInnerJoin(df DataFrame, keys ...string) GroupOption {
-	return func(other DataFrame) DataFrame {
-		return df.InnerJoin(other, keys...)
+// WithResetErr makes ConcatWithOptions skip the inputs holding an error
+// instead of failing with it.
+func WithResetErr() ConcatOption {
+	return func(c *concatOptions) {
+		c.resetErr = true
 	}
 }
 
-func WithCrossJoin(df DataFrame, opts ...nameSuffixOption) GroupOption {
-	return func(other DataFrame) DataFrame {
-		return df.CrossJoin(other, opts...)
+// ConcatWithOptions concatenates the rows of the given DataFrames like Concat.
+// By default an input holding an error, or one that can't be concatenated to
+// the previous ones, fails the whole result with an error naming its position.
+// WithResetErr skips the inputs holding an error instead, and WithSortColumns
+// orders the resulting columns by name.
+func ConcatWithOptions(dfs []DataFrame, options ...ConcatOption) DataFrame {
+	cfg := concatOptions{}
+	for _, option := range options {
+		option(&cfg)
 	}
-}
 
-// func GroupAggregate(df DataFrame, ons []string, fns []AggregationType, columns []string, opts ...GroupOption) DataFrame {
+	var result DataFrame
+	first := true
+	for i, df := range dfs {
+		if df.Err != nil {
+			if cfg.resetErr {
+				continue
+			}
+			return DataFrame{Err: fmt.Errorf("concat: input %d: %v", i, df.Err)}
+		}
+		if first {
+			result, first = df, false
+			continue
+		}
+		result = result.Concat(df)
+		if result.Err != nil {
+			return DataFrame{Err: fmt.Errorf("concat: input %d: %v", i, result.Err)}
+		}
+	}
+	if first {
+		return New()
+	}
+	if cfg.sortColumns {
+		names := result.Names()
+		sort.Strings(names)
+		result = result.Select(names)
+	}
+	return result
+}
+
+// Bind places the columns of the given DataFrames side by side, in order, with
+// CBind. All of them must have the same number of rows. A column name present
+// on several DataFrames gets the WithLeftSuffix suffix on its first occurrence
+// and the WithRightSuffix suffix on the later ones, numbered from the third
+// occurrence on: "x", "x_right", "x_right2", ...
+func Bind(dfs []DataFrame, opts ...nameSuffixOption) DataFrame {
+	if len(dfs) == 0 {
+		return New()
+	}
+	options := nameSuffinx{"", "_right"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	occurrences := make(map[string]int)
+	for _, df := range dfs {
+		if df.Err != nil {
+			return df
+		}
+		if df.nrows != dfs[0].nrows {
+			return DataFrame{Err: fmt.Errorf("bind: dimensions mismatch (%d != %d rows)", dfs[0].nrows, df.nrows)}
+		}
+		for _, name := range df.Names() {
+			occurrences[name]++
+		}
+	}
+
+	seen := make(map[string]int)
+	var result DataFrame
+	for k, df := range dfs {
+		df = df.Copy()
+		for i, col := range df.columns {
+			if occurrences[col.Name] < 2 {
+				continue
+			}
+			seen[col.Name]++
+			switch n := seen[col.Name]; {
+			case n == 1:
+				df.columns[i].Name += options.left
+			case n == 2:
+				df.columns[i].Name += options.right
+			default:
+				df.columns[i].Name += options.right + strconv.Itoa(n-1)
+			}
+		}
+		if k == 0 {
+			result = df
+		} else {
+			result = result.CBind(df)
+		}
+	}
+	return result
+}
+
+func contains[T int | float64 | string](s []T, str T) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupOption transforms the result of GroupAggregate or GroupCount, for
+// instance by joining it back to another DataFrame.
+type GroupOption func(DataFrame) DataFrame
+
+// GroupSetting configures how GroupAggregate and GroupCount form the groups
+type GroupSetting func(*groupOptions)
+
+// GroupAggregateOption is an option of GroupAggregate and GroupCount: either a
+// GroupOption applied to the result or a GroupSetting.
+type GroupAggregateOption interface {
+	applyGroup(*groupOptions)
+}
+
+func (o GroupOption) applyGroup(cfg *groupOptions) {
+	cfg.then = append(cfg.then, o)
+}
+
+func (s GroupSetting) applyGroup(cfg *groupOptions) {
+	s(cfg)
+}
+
+type groupOptions struct {
+	// dropNAKeys drops the rows where any of the group columns is NA before
+	// grouping them.
+	dropNAKeys bool
+
+	// aggNaming names the aggregated columns. If nil, they are named
+	// `<col>_<AGG>`.
+	aggNaming func(col string, agg AggregationType) string
+
+	// then holds the GroupOptions applied, in order, to the grouped result.
+	then []GroupOption
+}
+
+// newGroupOptions returns the group options with the defaults and the given
+// options applied.
+func newGroupOptions(opts []GroupAggregateOption) groupOptions {
+	cfg := groupOptions{dropNAKeys: true}
+	for _, opt := range opts {
+		opt.applyGroup(&cfg)
+	}
+	return cfg
+}
+
+// filter drops the rows with NA keys from df if dropNAKeys is set. Unknown
+// group columns are left for the grouping to report.
+func (o groupOptions) filter(df DataFrame, colnames []string) DataFrame {
+	if !o.dropNAKeys || len(colnames) == 0 {
+		return df
+	}
+	for _, c := range colnames {
+		if df.colIndex(c) < 0 {
+			return df
+		}
+	}
+	return df.DropNARows(colnames...)
+}
+
+// finish applies the GroupOptions to the grouped result.
+func (o groupOptions) finish(df DataFrame) DataFrame {
+	for _, opt := range o.then {
+		df = opt(df)
+	}
+	return df
+}
+
+func WithLeftJoin(df DataFrame, keys ...string) GroupOption {
+	return func(other DataFrame) DataFrame {
+		return df.LeftJoin(other, keys...)
+	}
+}
+
+func WithRightJoin(df DataFrame, keys ...string) GroupOption {
+	return func(other DataFrame) DataFrame {
+		return df.RightJoin(other, keys...)
+	}
+}
+
+func WithInnerJoin(df DataFrame, keys ...string) GroupOption {
+	return func(other DataFrame) DataFrame {
+		return df.InnerJoin(other, keys...)
+	}
+}
+
+func WithCrossJoin(df DataFrame, opts ...nameSuffixOption) GroupOption {
+	return func(other DataFrame) DataFrame {
+		return df.CrossJoin(other, opts...)
+	}
+}
+
+// WithAggNaming sets the aggNaming option for groupOptions. GroupAggregate
+// names every aggregated column namer(col, agg) instead of `<col>_<AGG>` as
+// it creates it; the group columns keep their names.
+func WithAggNaming(namer func(col string, agg AggregationType) string) GroupSetting {
+	return func(o *groupOptions) {
+		o.aggNaming = namer
+	}
+}
+
+// WithDropNAKeys sets the dropNAKeys option for groupOptions. GroupAggregate
+// and GroupCount drop the rows with NA keys by default. When they are kept,
+// rows with NA keys form a group of their own whose key is NA, rendered as
+// "NaN", and sorted last.
+func WithDropNAKeys(b bool) GroupSetting {
+	return func(o *groupOptions) {
+		o.dropNAKeys = b
+	}
+}
+
+// func GroupAggregate(df DataFrame, ons []string, fns []AggregationType, columns []string, opts ...GroupOption) DataFrame {
 // 	// 按 idx 分组并计算 pct_overlap 的最大值
 // 	groupedMax := df.GroupBy(ons...).Aggregation(fns, columns)
 // 	// groupedMax = groupedMax.Rename("pct_overlap", "max_overlap")
@@ -2806,19 +3993,147 @@ func AggreateOn(fns []AggregationType, columns []string) func() ([]AggregationTy
 	}
 }
 
-func GroupAggregate(df DataFrame, groupOn func() []string, aggOn func() ([]AggregationType, []string), opts ...GroupOption) DataFrame {
+// GroupAggregate groups df by the groupOn columns and aggregates the aggOn
+// columns, with the layout described in Groups.Aggregation: group keys sorted
+// ascending and aggregated columns in the requested order. Rows with NA keys
+// are dropped unless WithDropNAKeys(false) is given. The given options are
+// then applied to the result in order.
+func GroupAggregate(df DataFrame, groupOn func() []string, aggOn func() ([]AggregationType, []string), opts ...GroupAggregateOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := newGroupOptions(opts)
+	ons := groupOn()
+	df = cfg.filter(df, ons)
+
 	// 按 idx 分组并计算 pct_overlap 的最大值
 	fns, columns := aggOn()
-	groupedMax := df.GroupBy(groupOn()...).Aggregation(fns, columns)
+	groups := df.GroupBy(ons...)
+	if groups == nil {
+		return DataFrame{Err: fmt.Errorf("GroupAggregate: no group columns given")}
+	}
+	groups.aggNaming = cfg.aggNaming
+	groupedMax := groups.Aggregation(fns, columns)
 	// groupedMax = groupedMax.Rename("pct_overlap", "max_overlap")
 
 	// 将最大值合并回原始数据框
-	for _, opt := range opts {
-		groupedMax = opt(groupedMax)
+	return cfg.finish(groupedMax)
+}
+
+// GroupCount counts the rows of every group formed by the groupOn columns. The
+// result holds the group columns plus an Int `count` column, with one row per
+// group sorted by the group columns, like the output of GroupAggregate. As with
+// GroupAggregate, rows with NA keys are dropped unless WithDropNAKeys(false) is
+// given, and the other options are applied to the result.
+func GroupCount(df DataFrame, groupOn func() []string, opts ...GroupAggregateOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := newGroupOptions(opts)
+	colnames := groupOn()
+	df = cfg.filter(df, colnames)
+	_, groups, err := df.groupRowIndexes(colnames...)
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("GroupCount: %v", err)}
+	}
+	first := make([]int, len(groups))
+	counts := make([]int, len(groups))
+	for k, idx := range groups {
+		first[k] = idx[0]
+		counts[k] = len(idx)
+	}
+	columns := make([]series.Series, 0, len(colnames)+1)
+	order := make([]Order, len(colnames))
+	for j, c := range colnames {
+		columns = append(columns, df.columns[df.colIndex(c)].Subset(first))
+		order[j] = Sort(c)
+	}
+	columns = append(columns, series.New(counts, series.Int, "count"))
+
+	return cfg.finish(New(columns...).Arrange(order...))
+}
+
+// CrosstabOption is the type used to configure Crosstab
+type CrosstabOption func(*crosstabOptions)
+
+type crosstabOptions struct {
+	// dropNA skips every pair where either value is NA instead of counting NA
+	// as a category of its own.
+	dropNA bool
+}
+
+// CrosstabDropNA sets the dropNA option for crosstabOptions.
+func CrosstabDropNA(b bool) CrosstabOption {
+	return func(c *crosstabOptions) {
+		c.dropNA = b
+	}
+}
+
+// Crosstab builds the contingency table of two Series of the same length. The
+// first column holds the unique values of a and there is one Int column per
+// unique value of b, named after it, counting how often each pair occurs. Both
+// sets of values are sorted, with NA last as its own category unless
+// CrosstabDropNA is set.
+func Crosstab(a, b series.Series, options ...CrosstabOption) DataFrame {
+	cfg := crosstabOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if a.Err != nil {
+		return DataFrame{Err: fmt.Errorf("crosstab: argument has errors: %v", a.Err)}
+	}
+	if b.Err != nil {
+		return DataFrame{Err: fmt.Errorf("crosstab: argument has errors: %v", b.Err)}
+	}
+	if a.Len() != b.Len() {
+		return DataFrame{Err: fmt.Errorf("crosstab: dimensions mismatch (%d != %d)", a.Len(), b.Len())}
+	}
+
+	// levels returns the first index of every distinct value of s in sorted
+	// order, together with the position of each row's value in that list.
+	levels := func(s series.Series) ([]int, []int) {
+		var first []int
+		pos := make([]int, s.Len())
+		seen := make(map[string]int)
+		for _, i := range s.Order(false) {
+			e := s.Elem(i)
+			if cfg.dropNA && e.IsNA() {
+				pos[i] = -1
+				continue
+			}
+			key := e.String()
+			k, ok := seen[key]
+			if !ok {
+				k = len(first)
+				seen[key] = k
+				first = append(first, i)
+			}
+			pos[i] = k
+		}
+		return first, pos
+	}
+	rows, rowPos := levels(a)
+	cols, colPos := levels(b)
+
+	counts := make([][]int, len(cols))
+	for j := range counts {
+		counts[j] = make([]int, len(rows))
+	}
+	for i := 0; i < a.Len(); i++ {
+		if rowPos[i] == -1 || colPos[i] == -1 {
+			continue
+		}
+		counts[colPos[i]][rowPos[i]]++
 	}
 
-	return groupedMax
+	columns := make([]series.Series, 0, len(cols)+1)
+	columns = append(columns, a.Subset(rows))
+	for j, i := range cols {
+		columns = append(columns, series.New(counts[j], series.Int, b.Elem(i).String()))
+	}
+	return New(columns...)
 }
+
 func (df DataFrame) Transpose() DataFrame {
 	if df.Err != nil {
 		return df
@@ -2884,3 +4199,886 @@ func (df DataFrame) Transpose() DataFrame {
 	// 创建并返回新的DataFrame
 	return New(newColumns...)
 }
+
+// Unstack spreads a long DataFrame into a wide one. Every distinct value of the
+// index column becomes a row and every distinct value of the column column
+// becomes a new column, filled with the matching entries of the value column,
+// both in order of first appearance. Missing combinations are NA and repeated
+// ones are an error.
+func (df DataFrame) Unstack(index, column, value string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	for _, colname := range []string{index, column, value} {
+		if df.colIndex(colname) == -1 {
+			return DataFrame{Err: fmt.Errorf("unstack: colname %s doesn't exist", colname)}
+		}
+	}
+	_, rowGroups, err := df.groupRowIndexes(index)
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("unstack: %v", err)}
+	}
+	colKeys, colGroups, err := df.groupRowIndexes(column)
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("unstack: %v", err)}
+	}
+	rowOf := make([]int, df.nrows)
+	first := make([]int, len(rowGroups))
+	for r, idx := range rowGroups {
+		first[r] = idx[0]
+		for _, i := range idx {
+			rowOf[i] = r
+		}
+	}
+
+	values := df.columns[df.colIndex(value)]
+	columns := []series.Series{df.columns[df.colIndex(index)].Subset(first)}
+	for c, idx := range colGroups {
+		cells := make([]interface{}, len(rowGroups))
+		seen := make([]bool, len(rowGroups))
+		for _, i := range idx {
+			r := rowOf[i]
+			if seen[r] {
+				return DataFrame{Err: fmt.Errorf("unstack: duplicated entry for %s %q and %s %q",
+					index, df.columns[df.colIndex(index)].Elem(i), column, colKeys[c])}
+			}
+			seen[r] = true
+			if e := values.Elem(i); !e.IsNA() {
+				cells[r] = e
+			}
+		}
+		columns = append(columns, series.New(cells, values.Type(), colKeys[c]))
+	}
+	return New(columns...)
+}
+
+// Stack gathers every column not listed in idVars into a pair of "variable"
+// and "value" columns, producing one row per original row and gathered column.
+// The value column takes the widest type of the gathered columns, so gathering
+// Int and Float columns yields Float and only gathering a String column falls
+// back to String. Stack is the inverse of Unstack.
+func (df DataFrame) Stack(idVars []string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	idIdx := make([]int, len(idVars))
+	for i, colname := range idVars {
+		idIdx[i] = df.colIndex(colname)
+		if idIdx[i] == -1 {
+			return DataFrame{Err: fmt.Errorf("stack: colname %s doesn't exist", colname)}
+		}
+	}
+	var gathered []series.Series
+	var types []series.Type
+	for i, col := range df.columns {
+		if !inIntSlice(i, idIdx) {
+			gathered = append(gathered, col)
+			types = append(types, col.Type())
+		}
+	}
+	if len(gathered) == 0 {
+		return DataFrame{Err: fmt.Errorf("stack: no columns to gather")}
+	}
+
+	n := df.nrows * len(gathered)
+	rows := make([]int, 0, n)
+	names := make([]string, 0, n)
+	cells := make([]interface{}, 0, n)
+	for i := 0; i < df.nrows; i++ {
+		for _, col := range gathered {
+			rows = append(rows, i)
+			names = append(names, col.Name)
+			if e := col.Elem(i); e.IsNA() {
+				cells = append(cells, nil)
+			} else {
+				cells = append(cells, e)
+			}
+		}
+	}
+
+	columns := make([]series.Series, 0, len(idIdx)+2)
+	for _, i := range idIdx {
+		columns = append(columns, df.columns[i].Subset(rows))
+	}
+	columns = append(columns,
+		series.New(names, series.String, "variable"),
+		series.New(cells, commonType(types...), "value"),
+	)
+	return New(columns...)
+}
+
+// RunLengthEncode compresses consecutive runs of equal values of the given
+// column into a DataFrame with one row per run, holding the run "value" (with
+// the type of the column), its "start" row and its "length". Consecutive NA
+// elements form a single run.
+func (df DataFrame) RunLengthEncode(column string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	idx := df.colIndex(column)
+	if idx == -1 {
+		return DataFrame{Err: fmt.Errorf("run length encode: colname %s doesn't exist", column)}
+	}
+	col := df.columns[idx]
+	runs := col.Runs()
+	starts := make([]int, len(runs))
+	lengths := make([]int, len(runs))
+	for i, run := range runs {
+		starts[i], lengths[i] = run.Start, run.Length
+	}
+	value := col.Subset(starts)
+	value.Name = "value"
+	return New(
+		value,
+		series.New(starts, series.Int, "start"),
+		series.New(lengths, series.Int, "length"),
+	)
+}
+
+// NLargestBy returns the n rows with the largest values in the given column,
+// in descending order of that column. Ties keep their input order and rows
+// where the column is NA sink to the bottom. If n exceeds the number of rows
+// all of them are returned.
+func (df DataFrame) NLargestBy(n int, column string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if n < 0 {
+		return DataFrame{Err: fmt.Errorf("nlargest: n must be non-negative, got %d", n)}
+	}
+	idx := df.colIndex(column)
+	if idx == -1 {
+		return DataFrame{Err: fmt.Errorf("nlargest: colname %s doesn't exist", column)}
+	}
+	order := df.columns[idx].Order(true)
+	if n > len(order) {
+		n = len(order)
+	}
+	return df.Subset(order[:n])
+}
+
+// SelectByType returns the columns of the DataFrame whose type is one of the
+// given types, in their original order.
+func (df DataFrame) SelectByType(types ...series.Type) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	idx := []int{}
+	for i, col := range df.columns {
+		for _, t := range types {
+			if col.Type() == t {
+				idx = append(idx, i)
+				break
+			}
+		}
+	}
+	if len(idx) == 0 {
+		return DataFrame{Err: fmt.Errorf("select by type: no columns of types %v", types)}
+	}
+	return df.Select(idx)
+}
+
+// HashOption is the type used to configure DataFrame.Hash
+type HashOption func(*hashOptions)
+
+type hashOptions struct {
+	// orderIndependent makes the hash ignore the order of the columns.
+	orderIndependent bool
+}
+
+// HashOrderIndependent sets the orderIndependent option for hashOptions.
+func HashOrderIndependent(b bool) HashOption {
+	return func(h *hashOptions) {
+		h.orderIndependent = b
+	}
+}
+
+// Hash returns a stable 64-bit fingerprint of the DataFrame built from the
+// Hash of each of its columns. By default the column order is part of the
+// fingerprint; with HashOrderIndependent reordering the columns keeps it.
+func (df DataFrame) Hash(options ...HashOption) uint64 {
+	cfg := hashOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if cfg.orderIndependent {
+		var sum uint64
+		for _, col := range df.columns {
+			sum += col.Hash()
+		}
+		return sum
+	}
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, col := range df.columns {
+		binary.LittleEndian.PutUint64(buf[:], col.Hash())
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// AssertNoNA returns an error listing every one of the given columns, or every
+// column of the DataFrame if none is given, that holds NA elements together
+// with how many of them it has. It returns nil if none of them do.
+func (df DataFrame) AssertNoNA(columns ...string) error {
+	if df.Err != nil {
+		return df.Err
+	}
+	if len(columns) == 0 {
+		columns = df.Names()
+	}
+	var dirty []string
+	for _, colname := range columns {
+		idx := df.colIndex(colname)
+		if idx == -1 {
+			return fmt.Errorf("assert no NA: colname %s doesn't exist", colname)
+		}
+		count := 0
+		for _, na := range df.columns[idx].IsNaN() {
+			if na {
+				count++
+			}
+		}
+		if count > 0 {
+			dirty = append(dirty, fmt.Sprintf("%s (%d)", colname, count))
+		}
+	}
+	if len(dirty) > 0 {
+		return fmt.Errorf("assert no NA: columns with NA values: %s", strings.Join(dirty, ", "))
+	}
+	return nil
+}
+
+// AssertUnique returns an error if the combination of the given columns, or
+// of every column if none is given, is not unique across the rows. The error
+// lists the first five duplicated keys with the rows holding them.
+func (df DataFrame) AssertUnique(columns ...string) error {
+	if df.Err != nil {
+		return df.Err
+	}
+	if len(columns) == 0 {
+		columns = df.Names()
+	}
+	_, groups, err := df.groupRowIndexes(columns...)
+	if err != nil {
+		return fmt.Errorf("assert unique: %v", err)
+	}
+	var dups []string
+	count := 0
+	for _, idx := range groups {
+		if len(idx) < 2 {
+			continue
+		}
+		count++
+		if len(dups) == 5 {
+			continue
+		}
+		values := make([]string, len(columns))
+		for j, c := range columns {
+			values[j] = df.columns[df.colIndex(c)].Elem(idx[0]).String()
+		}
+		rows := make([]string, len(idx))
+		for k, i := range idx {
+			rows[k] = strconv.Itoa(i)
+		}
+		dups = append(dups, fmt.Sprintf("(%s) at rows %s", strings.Join(values, ", "), strings.Join(rows, ", ")))
+	}
+	if count == 0 {
+		return nil
+	}
+	if count > len(dups) {
+		dups = append(dups, fmt.Sprintf("and %d more", count-len(dups)))
+	}
+	return fmt.Errorf("assert unique: duplicated keys on %s: %s", strings.Join(columns, ", "), strings.Join(dups, "; "))
+}
+
+// OHEOption is the type used to configure OneHotEncode
+type OHEOption func(*oheOptions)
+
+type oheOptions struct {
+	// dropOriginal removes the encoded column from the result.
+	dropOriginal bool
+
+	// dropFirst skips the indicator of the first level to avoid collinearity.
+	dropFirst bool
+
+	// asBool makes the indicator columns Bool instead of Int.
+	asBool bool
+}
+
+// OHEDropOriginal sets the dropOriginal option for oheOptions.
+func OHEDropOriginal(b bool) OHEOption {
+	return func(c *oheOptions) {
+		c.dropOriginal = b
+	}
+}
+
+// OHEDropFirst sets the dropFirst option for oheOptions.
+func OHEDropFirst(b bool) OHEOption {
+	return func(c *oheOptions) {
+		c.dropFirst = b
+	}
+}
+
+// OHEAsBool sets the asBool option for oheOptions.
+func OHEAsBool(b bool) OHEOption {
+	return func(c *oheOptions) {
+		c.asBool = b
+	}
+}
+
+// OneHotEncode appends one indicator column per distinct value of the given
+// column, named `<column>_<value>` and holding 1 on the rows with that value
+// and 0 elsewhere. Levels are sorted and NA is not a level, so NA rows are 0 on
+// every indicator. Indicators are Int unless OHEAsBool is set.
+func (df DataFrame) OneHotEncode(column string, options ...OHEOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := oheOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	idx := df.colIndex(column)
+	if idx == -1 {
+		return DataFrame{Err: fmt.Errorf("one hot encode: colname %s doesn't exist", column)}
+	}
+	col := df.columns[idx]
+
+	var levels []string
+	pos := make(map[string]int)
+	for _, i := range col.Order(false) {
+		e := col.Elem(i)
+		if e.IsNA() {
+			continue
+		}
+		if _, ok := pos[e.String()]; !ok {
+			pos[e.String()] = len(levels)
+			levels = append(levels, e.String())
+		}
+	}
+
+	indicators := make([][]int, len(levels))
+	for k := range indicators {
+		indicators[k] = make([]int, df.nrows)
+	}
+	for i := 0; i < df.nrows; i++ {
+		if e := col.Elem(i); !e.IsNA() {
+			indicators[pos[e.String()]][i] = 1
+		}
+	}
+
+	columns := make([]series.Series, 0, df.ncols+len(levels))
+	for i, c := range df.columns {
+		if i != idx || !cfg.dropOriginal {
+			columns = append(columns, c)
+		}
+	}
+	t := series.Int
+	if cfg.asBool {
+		t = series.Bool
+	}
+	for k, level := range levels {
+		if k == 0 && cfg.dropFirst {
+			continue
+		}
+		columns = append(columns, series.New(indicators[k], t, column+"_"+level))
+	}
+	return New(columns...)
+}
+
+// ValidateRanges checks that the values of every numeric column named in ranges
+// fall within its inclusive [min, max] bounds. It returns an error listing, in
+// column name order, every column holding values out of range together with
+// the first offending row indexes, or nil if all of them are within range. NA
+// cells are skipped.
+func (df DataFrame) ValidateRanges(ranges map[string][2]float64) error {
+	if df.Err != nil {
+		return df.Err
+	}
+	const maxExamples = 5
+	colnames := make([]string, 0, len(ranges))
+	for colname := range ranges {
+		colnames = append(colnames, colname)
+	}
+	sort.Strings(colnames)
+
+	var invalid []string
+	for _, colname := range colnames {
+		idx := df.colIndex(colname)
+		if idx == -1 {
+			return fmt.Errorf("validate ranges: colname %s doesn't exist", colname)
+		}
+		col := df.columns[idx]
+		if t := col.Type(); t != series.Int && t != series.Float {
+			return fmt.Errorf("validate ranges: column %s is not numeric", colname)
+		}
+		bounds := ranges[colname]
+		count := 0
+		var rows []string
+		for i := 0; i < df.nrows; i++ {
+			e := col.Elem(i)
+			if e.IsNA() {
+				continue
+			}
+			if f := e.Float(); f < bounds[0] || f > bounds[1] {
+				count++
+				if len(rows) < maxExamples {
+					rows = append(rows, strconv.Itoa(i))
+				}
+			}
+		}
+		if count > 0 {
+			invalid = append(invalid, fmt.Sprintf("%s: %d values outside [%v, %v] (rows %s)",
+				colname, count, bounds[0], bounds[1], strings.Join(rows, ", ")))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("validate ranges: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+// InferOption is the type used to configure InferTypes
+type InferOption func(*inferOptions)
+
+type inferOptions struct {
+	// columns restricts the inference to the given columns.
+	columns []string
+}
+
+// InferColumns sets the columns option for inferOptions.
+func InferColumns(columns ...string) InferOption {
+	return func(c *inferOptions) {
+		c.columns = columns
+	}
+}
+
+// InferTypes converts String columns to the type LoadRecords would detect for
+// them: columns holding only integers become Int, integers mixed with decimals
+// become Float and "true"/"false" values make the column Bool. Columns with any
+// other value, or no value at all, stay String.
+func (df DataFrame) InferTypes(options ...InferOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := inferOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	for _, col := range cfg.columns {
+		if df.colIndex(col) < 0 {
+			return DataFrame{Err: fmt.Errorf("infer types: can't find column name %q", col)}
+		}
+	}
+
+	columns := make([]series.Series, df.ncols)
+	for j, s := range df.columns {
+		columns[j] = s
+		if s.Type() != series.String || (cfg.columns != nil && findInStringSlice(s.Name, cfg.columns) < 0) {
+			continue
+		}
+		records := s.Records()
+		if t, err := findType(records); err == nil && t != series.String {
+			columns[j] = series.New(records, t, s.Name)
+		}
+	}
+	return New(columns...)
+}
+
+// CumAgg appends one `<col>_cum<AGG>` Float column per given numeric column,
+// holding its running SUM, PROD, MAX or MIN. NA elements are skipped and stay
+// NA in the new columns.
+func (df DataFrame) CumAgg(agg AggregationType, columns ...string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	var cumulate func(series.Series) series.Series
+	switch agg {
+	case Aggregation_SUM:
+		cumulate = series.Series.CumSum
+	case Aggregation_PROD:
+		cumulate = series.Series.CumProd
+	case Aggregation_MAX:
+		cumulate = series.Series.CumMax
+	case Aggregation_MIN:
+		cumulate = series.Series.CumMin
+	default:
+		return DataFrame{Err: fmt.Errorf("cumagg: unsupported aggregation %s", agg)}
+	}
+	ret := df.Copy()
+	for _, col := range columns {
+		idx := df.colIndex(col)
+		if idx < 0 {
+			return DataFrame{Err: fmt.Errorf("cumagg: can't find column name %q", col)}
+		}
+		s := cumulate(df.columns[idx])
+		if s.Err != nil {
+			return DataFrame{Err: fmt.Errorf("cumagg: column %q: %v", col, s.Err)}
+		}
+		s.Name = fmt.Sprintf("%s_cum%s", col, agg)
+		ret = ret.Mutate(s)
+	}
+	return ret
+}
+
+// LatestBy keeps, for every combination of values of keyCols, the row with the
+// highest orderCol value, with all its columns. It is the same as sorting by
+// orderCol in descending order and dropping the duplicated keys keeping the
+// first row. Ties keep the first row in the original order and NA orderCol
+// values sort last.
+func (df DataFrame) LatestBy(keyCols []string, orderCol string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if len(keyCols) == 0 {
+		return DataFrame{Err: fmt.Errorf("latest by: no key columns given")}
+	}
+	sorted := df.Arrange(RevSort(orderCol))
+	if sorted.Err != nil {
+		return DataFrame{Err: fmt.Errorf("latest by: %v", sorted.Err)}
+	}
+	_, groups, err := sorted.groupRowIndexes(keyCols...)
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("latest by: %v", err)}
+	}
+	firsts := make([]int, len(groups))
+	for i, idx := range groups {
+		firsts[i] = idx[0]
+	}
+	return sorted.Subset(firsts)
+}
+
+// CorrWith returns the Pearson correlation of every other Int or Float column
+// with the target column, as a Float Series named after the target whose
+// elements follow the order of those columns in the DataFrame. Rows where
+// either value is NA are dropped pairwise, and columns with fewer than two
+// remaining pairs get NaN.
+func (df DataFrame) CorrWith(target string) series.Series {
+	if df.Err != nil {
+		return series.Series{Err: df.Err}
+	}
+	idx := df.colIndex(target)
+	if idx < 0 {
+		return series.Series{Err: fmt.Errorf("corr with: can't find column name %q", target)}
+	}
+	y := df.columns[idx]
+	if t := y.Type(); t != series.Int && t != series.Float {
+		return series.Series{Err: fmt.Errorf("corr with: unsupported target type %s", t)}
+	}
+	corrs := []float64{}
+	for j, col := range df.columns {
+		if j == idx || (col.Type() != series.Int && col.Type() != series.Float) {
+			continue
+		}
+		var a, b []float64
+		for i := 0; i < df.nrows; i++ {
+			ea, eb := col.Elem(i), y.Elem(i)
+			if ea.IsNA() || eb.IsNA() {
+				continue
+			}
+			a = append(a, ea.Float())
+			b = append(b, eb.Float())
+		}
+		corr := math.NaN()
+		if len(a) >= 2 {
+			corr = stat.Correlation(a, b, nil)
+		}
+		corrs = append(corrs, corr)
+	}
+	return series.New(corrs, series.Float, target)
+}
+
+// ReindexOption is the type used to configure Reindex
+type ReindexOption func(*reindexOptions)
+
+type reindexOptions struct {
+	// types holds the type of the columns created by Reindex.
+	types map[string]series.Type
+}
+
+// ReindexTypes sets the types option for reindexOptions.
+func ReindexTypes(types map[string]series.Type) ReindexOption {
+	return func(c *reindexOptions) {
+		c.types = types
+	}
+}
+
+// Reindex returns a DataFrame with exactly the given columns, in that order.
+// Columns of the DataFrame that are not listed are dropped and listed columns
+// it lacks are created full of NA, of type String unless ReindexTypes says
+// otherwise. It is handy to bring frames to a common schema before Concat.
+func (df DataFrame) Reindex(columns []string, options ...ReindexOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := reindexOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if len(columns) == 0 {
+		return DataFrame{Err: fmt.Errorf("reindex: no columns given")}
+	}
+	seen := make(map[string]bool, len(columns))
+	ret := make([]series.Series, len(columns))
+	for i, c := range columns {
+		if seen[c] {
+			return DataFrame{Err: fmt.Errorf("reindex: duplicated column name %q", c)}
+		}
+		seen[c] = true
+		if idx := df.colIndex(c); idx >= 0 {
+			ret[i] = df.columns[idx]
+			continue
+		}
+		t, ok := cfg.types[c]
+		if !ok {
+			t = series.String
+		}
+		ret[i] = series.New(make([]interface{}, df.nrows), t, c)
+	}
+	return New(ret...)
+}
+
+// rowIndex maps the labels of the index column of a DataFrame to the rows
+// holding them.
+type rowIndex struct {
+	column string
+	rows   map[string][]int
+}
+
+// newRowIndex builds the index of the given column, named column.
+func newRowIndex(column string, s series.Series) *rowIndex {
+	rows := make(map[string][]int)
+	for i := 0; i < s.Len(); i++ {
+		if e := s.Elem(i); !e.IsNA() {
+			rows[e.String()] = append(rows[e.String()], i)
+		}
+	}
+	return &rowIndex{column: column, rows: rows}
+}
+
+// indexKey returns the key under which a label is stored in the index, after
+// converting it to the type of the index column.
+func indexKey(label interface{}, t series.Type) (string, bool) {
+	e := series.New([]interface{}{label}, t, "").Elem(0)
+	return e.String(), !e.IsNA()
+}
+
+// SetIndex marks the given column as the row index of the DataFrame, enabling
+// label based access through Loc and At. The column stays a regular column as
+// well. Copy, Rename and Set keep the index up to date, but operations building
+// a new DataFrame, such as Subset, Arrange or Mutate, don't carry it over.
+func (df DataFrame) SetIndex(column string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	idx := df.colIndex(column)
+	if idx < 0 {
+		return DataFrame{Err: fmt.Errorf("set index: can't find column name %q", column)}
+	}
+	ret := df.Copy()
+	ret.index = newRowIndex(column, ret.columns[idx])
+	return ret
+}
+
+// Index returns the name of the index column set by SetIndex, or the empty
+// string if there is none.
+func (df DataFrame) Index() string {
+	if df.index == nil {
+		return ""
+	}
+	return df.index.column
+}
+
+// ResetIndex returns the DataFrame without row index, leaving the former index
+// column as a regular column.
+func (df DataFrame) ResetIndex() DataFrame {
+	ret := df
+	ret.index = nil
+	return ret
+}
+
+// Loc returns the rows whose index label matches the given one, converted to
+// the type of the index column. It fails if no index is set or no row has the
+// label.
+func (df DataFrame) Loc(label interface{}) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	rows, err := df.locRows(label)
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("loc: %v", err)}
+	}
+	return df.Subset(rows)
+}
+
+func (df DataFrame) locRows(label interface{}) ([]int, error) {
+	if df.index == nil {
+		return nil, fmt.Errorf("no index set")
+	}
+	key, ok := indexKey(label, df.columns[df.colIndex(df.index.column)].Type())
+	rows := df.index.rows[key]
+	if !ok || len(rows) == 0 {
+		return nil, fmt.Errorf("label %v not found in index %q", label, df.index.column)
+	}
+	return rows, nil
+}
+
+// At returns the value of the given column on the row with the given index
+// label, or nil for an NA element. The row is found through the index built by
+// SetIndex without scanning the DataFrame; if several rows share the label the
+// first one is used.
+func (df DataFrame) At(label interface{}, column string) (interface{}, error) {
+	if df.Err != nil {
+		return nil, df.Err
+	}
+	idx := df.colIndex(column)
+	if idx < 0 {
+		return nil, fmt.Errorf("at: can't find column name %q", column)
+	}
+	rows, err := df.locRows(label)
+	if err != nil {
+		return nil, fmt.Errorf("at: %v", err)
+	}
+	return df.columns[idx].Val(rows[0]), nil
+}
+
+// DropNARows returns the rows that have no NA element in any of the given
+// columns, or in any column if none is given.
+func (df DataFrame) DropNARows(columns ...string) DataFrame {
+	return df.filterNARows("drop NA rows", false, columns...)
+}
+
+// KeepNARows returns the rows that have an NA element in at least one of the
+// given columns, or in any column if none is given.
+func (df DataFrame) KeepNARows(columns ...string) DataFrame {
+	return df.filterNARows("keep NA rows", true, columns...)
+}
+
+func (df DataFrame) filterNARows(op string, keepNA bool, columns ...string) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	if len(columns) == 0 {
+		columns = df.Names()
+	}
+	hasNA := make([]bool, df.nrows)
+	for _, c := range columns {
+		idx := df.colIndex(c)
+		if idx < 0 {
+			return DataFrame{Err: fmt.Errorf("%s: can't find column name %q", op, c)}
+		}
+		for i, na := range df.columns[idx].IsNaN() {
+			hasNA[i] = hasNA[i] || na
+		}
+	}
+	rows := []int{}
+	for i, na := range hasNA {
+		if na == keepNA {
+			rows = append(rows, i)
+		}
+	}
+	return df.Subset(rows)
+}
+
+// SampleOption is the type used to configure SampleWeighted
+type SampleOption func(*sampleOptions)
+
+type sampleOptions struct {
+	// zeroInvalid treats negative and NA weights as zero instead of failing
+	zeroInvalid bool
+}
+
+// SampleZeroInvalid sets the zeroInvalid option for sampleOptions.
+func SampleZeroInvalid(b bool) SampleOption {
+	return func(o *sampleOptions) {
+		o.zeroInvalid = b
+	}
+}
+
+// SampleWeighted draws n rows with probability proportional to the values of
+// the numeric column weightCol, using a random source seeded with seed so the
+// draw is reproducible. With replace a row may be drawn several times;
+// without it every row is drawn at most once and n can't exceed the number of
+// rows of positive weight. Rows are returned in draw order. Negative and NA
+// weights are an error unless SampleZeroInvalid is set.
+func (df DataFrame) SampleWeighted(n int, weightCol string, seed int64, replace bool, options ...SampleOption) DataFrame {
+	if df.Err != nil {
+		return df
+	}
+	cfg := sampleOptions{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if n < 0 {
+		return DataFrame{Err: fmt.Errorf("sample weighted: n must not be negative, got %d", n)}
+	}
+	idx := df.colIndex(weightCol)
+	if idx < 0 {
+		return DataFrame{Err: fmt.Errorf("sample weighted: can't find column name %q", weightCol)}
+	}
+	col := df.columns[idx]
+	if col.Type() != series.Int && col.Type() != series.Float {
+		return DataFrame{Err: fmt.Errorf("sample weighted: column %q is not numeric", weightCol)}
+	}
+
+	weights := make([]float64, df.nrows)
+	total, positive := 0.0, 0
+	for i := range weights {
+		e := col.Elem(i)
+		w := e.Float()
+		if e.IsNA() || w < 0 || math.IsNaN(w) {
+			if !cfg.zeroInvalid {
+				return DataFrame{Err: fmt.Errorf("sample weighted: invalid weight %s at row %d", e, i)}
+			}
+			w = 0
+		}
+		weights[i] = w
+		total += w
+		if w > 0 {
+			positive++
+		}
+	}
+	if n > 0 && total == 0 {
+		return DataFrame{Err: fmt.Errorf("sample weighted: all weights are zero")}
+	}
+	if !replace && n > positive {
+		return DataFrame{Err: fmt.Errorf("sample weighted: can't draw %d rows without replacement from %d rows of positive weight", n, positive)}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	rows := make([]int, 0, n)
+	if replace {
+		cumulative := make([]float64, df.nrows)
+		acc := 0.0
+		for i, w := range weights {
+			acc += w
+			cumulative[i] = acc
+		}
+		for len(rows) < n {
+			i := sort.SearchFloat64s(cumulative, rng.Float64()*total)
+			// A draw of exactly zero lands on leading zero weight rows.
+			for weights[i] == 0 {
+				i++
+			}
+			rows = append(rows, i)
+		}
+		return df.Subset(rows)
+	}
+
+	// Efraimidis-Spirakis: the n rows with the largest u^(1/w) keys form a
+	// weighted sample without replacement, in draw order.
+	keys := make([]float64, df.nrows)
+	candidates := make([]int, 0, positive)
+	for i, w := range weights {
+		if w > 0 {
+			keys[i] = math.Pow(rng.Float64(), 1/w)
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return keys[candidates[a]] > keys[candidates[b]]
+	})
+	rows = append(rows, candidates[:n]...)
+	return df.Subset(rows)
+}
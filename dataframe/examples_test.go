@@ -320,7 +320,7 @@ func ExampleDataFrame_Mutate() {
 	//  2: k        4     c        true
 	//  3: a        2     d        false
 	//     <string> <int> <string> <bool>
-
+	//
 	// [4x5] DataFrame
 	//
 	//     A        B     C        D      E
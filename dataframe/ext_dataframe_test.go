@@ -1,7 +1,11 @@
 package dataframe
 
 import (
+	"bytes"
+	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/netxops/frame/series"
@@ -151,6 +155,26 @@ func TestMax(t *testing.T) {
 	}
 }
 
+func TestMinMaxInColumnsNA(t *testing.T) {
+	df := New(
+		series.New([]interface{}{nil, nil, 4, 1}, series.Int, "A"),
+		series.New([]interface{}{nil, 7, nil, 3}, series.Int, "B"),
+		series.New([]interface{}{nil, nil, nil, 2}, series.Int, "C"),
+		series.New([]string{"a", "b", "c", "d"}, series.String, "D"),
+	)
+
+	// Row 0 is NA everywhere, rows 1 and 2 have a single valid cell.
+	min := MinInColumns(df, "min", "A", "B", "C")
+	assert.Equal(t, []string{"NaN", "7", "4", "1"}, min.Records())
+
+	max := MaxInColumns(df, "max", "A", "B", "C")
+	assert.Equal(t, []string{"NaN", "7", "4", "3"}, max.Records())
+
+	// An ignored leading column must not break the selection.
+	max = MaxInColumns(df, "max", "D", "B", "C")
+	assert.Equal(t, []string{"NaN", "7", "NaN", "3"}, max.Records())
+}
+
 func TestRowIterator(t *testing.T) {
 	// 创建一个测试用的DataFrame
 	df := New(
@@ -230,6 +254,38 @@ func TestRowIterator(t *testing.T) {
 	})
 }
 
+func TestEachRow(t *testing.T) {
+	df := New(
+		series.New([]int{1, 2, 3}, series.Int, "A"),
+		series.New([]string{"a", "b", "c"}, series.String, "C"),
+	)
+
+	t.Run("Stops when the callback returns false", func(t *testing.T) {
+		var visited []int
+		df.EachRow(func(i int, row map[string]interface{}) bool {
+			visited = append(visited, i)
+			return i < 1
+		})
+		assert.Equal(t, []int{0, 1}, visited)
+	})
+
+	t.Run("Visits all rows with selected columns", func(t *testing.T) {
+		var rows []map[string]interface{}
+		df.EachRow(func(i int, row map[string]interface{}) bool {
+			rows = append(rows, row)
+			return true
+		}, WithSelectedColumns("C"))
+		assert.Equal(t, []map[string]interface{}{{"C": "a"}, {"C": "b"}, {"C": "c"}}, rows)
+	})
+
+	t.Run("Without row index", func(t *testing.T) {
+		df.EachRow(func(i int, row map[string]interface{}) bool {
+			assert.Equal(t, -1, i)
+			return true
+		}, WithRowIndex(false))
+	})
+}
+
 func TestDistinct(t *testing.T) {
 	// 创建一个包含重复行的DataFrame
 	df := New(
@@ -429,8 +485,8 @@ func TestGroupAggregate(t *testing.T) {
 			series.New([]string{"A", "B"}, series.String, "category"),
 			// series.New([]float64{5, 4}, series.Float, "value_max"),
 			// series.New([]float64{0.3, 0.3}, series.Float, "pct_overlap_avg"),
-			series.New([]float64{0.5, 0.4}, series.Float, "pct_overlap_MAX"),
 			series.New([]float64{3, 3}, series.Float, "value_MEAN"),
+			series.New([]float64{0.5, 0.4}, series.Float, "pct_overlap_MAX"),
 		)
 
 		assert.True(t, expected.Equal(result))
@@ -448,14 +504,106 @@ func TestGroupAggregate(t *testing.T) {
 			series.New([]string{"A", "B", "A", "B", "A"}, series.String, "category"),
 			series.New([]int{1, 2, 3, 4, 5}, series.Int, "value"),
 			series.New([]float64{0.1, 0.2, 0.3, 0.4, 0.5}, series.Float, "pct_overlap"),
-			series.New([]float64{0.5, 0.4, 0.5, 0.4, 0.5}, series.Float, "pct_overlap_MAX"),
 			series.New([]float64{3, 3, 3, 3, 3}, series.Float, "value_MEAN"),
+			series.New([]float64{0.5, 0.4, 0.5, 0.4, 0.5}, series.Float, "pct_overlap_MAX"),
 		)
 
 		assert.Equal(t, expected.Names(), result.Names())
 		assert.Equal(t, expected.Records(), result.Records())
 	})
 
+	t.Run("GroupAggregate with custom naming", func(t *testing.T) {
+		namer := func(col string, agg AggregationType) string {
+			return strings.ToLower(agg.String()) + "_" + col
+		}
+		result := GroupAggregate(df,
+			GroupOn("category"),
+			AggreateOn([]AggregationType{Aggregation_MEAN, Aggregation_MAX}, []string{"value", "pct_overlap"}),
+			WithAggNaming(namer),
+			WithLeftJoin(df, "category"))
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"category", "value", "pct_overlap", "mean_value", "max_pct_overlap"}, result.Names())
+		every := make([]AggregationType, 0, aggregationCount-1)
+		cols := make([]string, 0, aggregationCount-1)
+		for agg := Aggregation_MAX; agg < aggregationCount; agg++ {
+			if agg != Aggregation_CONCAT {
+				every = append(every, agg)
+				cols = append(cols, "value")
+			}
+		}
+		result = GroupAggregate(df, GroupOn("category"), AggreateOn(every, cols), WithAggNaming(namer))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"category", "max_value", "min_value", "mean_value", "median_value",
+			"std_value", "sum_value", "count_value", "prod_value"}, result.Names())
+
+		// group columns ending in an aggregation suffix keep their name
+		suffixed := df.Rename("category_SUM", "category")
+		result = GroupAggregate(suffixed,
+			GroupOn("category_SUM"),
+			AggreateOn([]AggregationType{Aggregation_SUM}, []string{"value"}),
+			WithAggNaming(namer))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"category_SUM", "sum_value"}, result.Names())
+	})
+
+	t.Run("GroupAggregate layout is deterministic", func(t *testing.T) {
+		df := New(
+			series.New([]string{"c", "a", "b", "c", "a", "b"}, series.String, "site"),
+			series.New([]int{1, 2, 3, 4, 5, 6}, series.Int, "flaps"),
+			series.New([]float64{0.5, 1, 1.5, 2, 2.5, 3}, series.Float, "load"),
+		)
+		for i := 0; i < 10; i++ {
+			result := GroupAggregate(df,
+				GroupOn("site"),
+				AggreateOn([]AggregationType{Aggregation_SUM, Aggregation_MAX, Aggregation_MIN}, []string{"load", "flaps", "load"}))
+			assert.NoError(t, result.Err)
+			assert.Equal(t, [][]string{
+				{"site", "load_SUM", "flaps_MAX", "load_MIN"},
+				{"a", "3.500000", "5.000000", "1.000000"},
+				{"b", "4.500000", "6.000000", "1.500000"},
+				{"c", "2.500000", "4.000000", "0.500000"},
+			}, result.Records())
+		}
+	})
+
+	t.Run("GroupAggregate with multiple group columns", func(t *testing.T) {
+		df := New(
+			series.New([]string{"r1", "r2", "r1", "r1_x", "r2", "r1"}, series.String, "region"),
+			series.New([]string{"up", "up", "x_up", "up", "up", "up"}, series.String, "status"),
+			series.New([]int{1, 2, 3, 4, 5, 6}, series.Int, "flaps"),
+		)
+		result := GroupAggregate(df,
+			GroupOn("region", "status"),
+			AggreateOn([]AggregationType{Aggregation_SUM, Aggregation_COUNT}, []string{"flaps", "flaps"}))
+		assert.NoError(t, result.Err)
+		// ("r1", "x_up") and ("r1_x", "up") share a readable key but stay apart.
+		assert.Equal(t, [][]string{
+			{"region", "status", "flaps_SUM", "flaps_COUNT"},
+			{"r1", "up", "7.000000", "2.000000"},
+			{"r1", "x_up", "3.000000", "1.000000"},
+			{"r1_x", "up", "4.000000", "1.000000"},
+			{"r2", "up", "7.000000", "2.000000"},
+		}, result.Records())
+
+		joined := GroupAggregate(df,
+			GroupOn("region", "status"),
+			AggreateOn([]AggregationType{Aggregation_SUM}, []string{"flaps"}),
+			WithLeftJoin(df, "region", "status"))
+		assert.NoError(t, joined.Err)
+		assert.Equal(t, []string{"7.000000", "7.000000", "3.000000", "4.000000", "7.000000", "7.000000"}, joined.Col("flaps_SUM").Records())
+
+		bools := New(
+			series.New([]bool{true, false, true}, series.Bool, "up"),
+			series.New([]int{1, 1, 1}, series.Int, "vlan"),
+			series.New([]int{1, 2, 3}, series.Int, "flaps"),
+		)
+		groups := bools.GroupBy("up", "vlan").GetGroups()
+		assert.Equal(t, 2, len(groups))
+		assert.Equal(t, 2, groups["true_1"].Nrow())
+		assert.Equal(t, 1, groups["false_1"].Nrow())
+	})
+
 	// // 测试带有多个聚合函数的 GroupAggregate
 	// t.Run("GroupAggregate with multiple aggregations", func(t *testing.T) {
 	// 	result := GroupAggregate(df, []string{"category"}, []AggregationType{Aggregation_MEAN, Aggregation_MAX, Aggregation_MIN}, []string{"value", "pct_overlap"})
@@ -474,6 +622,83 @@ func TestGroupAggregate(t *testing.T) {
 	// 	assert.Equal(t, expected.Records(), result.Records())
 	// })
 }
+func TestGroupCount(t *testing.T) {
+	df := New(
+		series.New([]string{"up", "down", "up", "up", "down", "lab"}, series.String, "status"),
+		series.New([]string{"r1", "r1", "r2", "r1", "r2", "r2"}, series.String, "region"),
+	)
+
+	t.Run("Single String column", func(t *testing.T) {
+		result := GroupCount(df, GroupOn("status"))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"status", "count"}, result.Names())
+		assert.Equal(t, []series.Type{series.String, series.Int}, result.Types())
+		assert.Equal(t, [][]string{
+			{"status", "count"},
+			{"down", "2"},
+			{"lab", "1"},
+			{"up", "3"},
+		}, result.Records())
+	})
+
+	t.Run("Multiple columns", func(t *testing.T) {
+		result := GroupCount(df, GroupOn("region", "status"))
+		assert.Equal(t, [][]string{
+			{"region", "status", "count"},
+			{"r1", "down", "1"},
+			{"r1", "up", "2"},
+			{"r2", "down", "1"},
+			{"r2", "lab", "1"},
+			{"r2", "up", "1"},
+		}, result.Records())
+	})
+
+	t.Run("Values holding the key separator", func(t *testing.T) {
+		ambiguous := New(
+			series.New([]string{"a_b", "a", "a_b"}, series.String, "site"),
+			series.New([]string{"c", "b_c", "c"}, series.String, "device"),
+		)
+		result := GroupCount(ambiguous, GroupOn("site", "device"))
+		assert.Equal(t, [][]string{
+			{"site", "device", "count"},
+			{"a", "b_c", "1"},
+			{"a_b", "c", "2"},
+		}, result.Records())
+	})
+
+	t.Run("NA keys", func(t *testing.T) {
+		withNA := New(
+			series.New([]interface{}{"up", nil, "up", nil, "down"}, series.String, "status"),
+		)
+		result := GroupCount(withNA, GroupOn("status"))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, [][]string{
+			{"status", "count"},
+			{"down", "1"},
+			{"up", "2"},
+		}, result.Records())
+
+		result = GroupCount(withNA, GroupOn("status"), WithDropNAKeys(false))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, [][]string{
+			{"status", "count"},
+			{"down", "1"},
+			{"up", "2"},
+			{"NaN", "2"},
+		}, result.Records())
+	})
+
+	t.Run("With LeftJoin", func(t *testing.T) {
+		result := GroupCount(df, GroupOn("status"), WithLeftJoin(df, "status"))
+		assert.Equal(t, []string{"3", "2", "3", "3", "2", "1"}, result.Col("count").Records())
+	})
+
+	t.Run("Unknown column", func(t *testing.T) {
+		result := GroupCount(df, GroupOn("missing"))
+		assert.Error(t, result.Err)
+	})
+}
+
 func TestDataFrame_Transpose(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -546,3 +771,1244 @@ func TestDataFrame_Transpose(t *testing.T) {
 		})
 	}
 }
+
+func TestSortValues(t *testing.T) {
+	df := New(
+		series.New([]string{"b", "a", "b", "a", "b"}, series.String, "key"),
+		series.New([]int{10, 9, 2, 9, 10}, series.Int, "num"),
+		series.New([]string{"r0", "r1", "r2", "r3", "r4"}, series.String, "id"),
+	)
+
+	t.Run("numeric compared numerically", func(t *testing.T) {
+		result := df.SortValues([]string{"num"}, []bool{false})
+		assert.NoError(t, result.Err)
+		// Lexical comparison would put "10" before "2" and "9".
+		assert.Equal(t, []string{"2", "9", "9", "10", "10"}, result.Col("num").Records())
+	})
+
+	t.Run("stable across equal keys", func(t *testing.T) {
+		result := df.SortValues([]string{"key", "num"}, []bool{false, true})
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"r1", "r3", "r0", "r4", "r2"}, result.Col("id").Records())
+	})
+
+	t.Run("stable in reverse", func(t *testing.T) {
+		result := df.SortValues([]string{"key"}, []bool{true})
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"r0", "r2", "r4", "r1", "r3"}, result.Col("id").Records())
+	})
+
+	t.Run("mismatched reverse length", func(t *testing.T) {
+		result := df.SortValues([]string{"key", "num"}, []bool{true})
+		assert.Error(t, result.Err)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		result := df.SortValues([]string{"missing"}, []bool{false})
+		assert.Error(t, result.Err)
+	})
+}
+
+func TestCoalesce(t *testing.T) {
+	df := New(
+		series.New([]interface{}{1, nil, nil, nil}, series.Int, "a"),
+		series.New([]interface{}{nil, 2.5, nil, nil}, series.Float, "b"),
+		series.New([]interface{}{7, 8, 9, nil}, series.Int, "c"),
+	)
+
+	result := df.Coalesce("first", "a", "b", "c")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"a", "b", "c", "first"}, result.Names())
+
+	first := result.Col("first")
+	assert.Equal(t, series.Float, first.Type())
+	assert.Equal(t, []string{"1.000000", "2.500000", "9.000000", "NaN"}, first.Records())
+
+	t.Run("string promotion", func(t *testing.T) {
+		df := New(
+			series.New([]interface{}{nil, "x"}, series.String, "s"),
+			series.New([]interface{}{3, 4}, series.Int, "i"),
+		)
+		result := df.Coalesce("out", "s", "i")
+		assert.NoError(t, result.Err)
+		assert.Equal(t, series.String, result.Col("out").Type())
+		assert.Equal(t, []string{"3", "x"}, result.Col("out").Records())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		assert.Error(t, df.Coalesce("out", "a", "missing").Err)
+	})
+
+	t.Run("no columns", func(t *testing.T) {
+		assert.Error(t, df.Coalesce("out").Err)
+	})
+}
+
+func TestCrosstab(t *testing.T) {
+	a := series.New([]interface{}{"x", "y", "x", "x", nil, "y"}, series.String, "a")
+	b := series.New([]interface{}{"p", "q", "q", "p", "p", nil}, series.String, "b")
+
+	t.Run("NA as bucket", func(t *testing.T) {
+		result := Crosstab(a, b)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, [][]string{
+			{"a", "p", "q", "NaN"},
+			{"x", "2", "1", "0"},
+			{"y", "0", "1", "1"},
+			{"NaN", "1", "0", "0"},
+		}, result.Records())
+	})
+
+	t.Run("drop NA", func(t *testing.T) {
+		result := Crosstab(a, b, CrosstabDropNA(true))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, [][]string{
+			{"a", "p", "q"},
+			{"x", "2", "1"},
+			{"y", "0", "1"},
+		}, result.Records())
+	})
+
+	t.Run("dimensions mismatch", func(t *testing.T) {
+		result := Crosstab(a, series.New([]string{"p"}, series.String, "b"))
+		assert.Error(t, result.Err)
+	})
+}
+
+func TestPrint(t *testing.T) {
+	t.Run("no limits matches String", func(t *testing.T) {
+		df := LoadRecords([][]string{
+			{"A", "C", "D"},
+			{"1", "5.1", "true"},
+			{"NaN", "6.0", "true"},
+		})
+		assert.Equal(t, df.String(), df.Print(PrintOptions{}))
+	})
+
+	t.Run("truncation markers", func(t *testing.T) {
+		var columns []series.Series
+		for c := 0; c < 12; c++ {
+			values := make([]string, 15)
+			for r := range values {
+				values[r] = fmt.Sprintf("interfaces.eth%d.counters.value_%d", c, r)
+			}
+			columns = append(columns, series.New(values, series.String, fmt.Sprintf("interfaces.eth%d.counters", c)))
+		}
+		df := New(columns...)
+
+		out := df.Print(PrintOptions{MaxRows: 5, MaxColWidth: 12, MaxCols: 3})
+		lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+
+		assert.Equal(t, "[15x12] DataFrame", lines[0])
+		assert.Contains(t, out, "... (9 more cols)")
+		assert.Contains(t, out, "interface...")
+		assert.NotContains(t, out, "eth3")
+		// header, 5 rows, dots row and types row after the dims line
+		assert.Len(t, lines, 2+1+5+1+1+1)
+		assert.Equal(t, []string{"...", "...", "..."}, strings.Fields(lines[8]))
+		for _, line := range lines[2:] {
+			assert.True(t, len(line) <= 4+3*13, line)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		df := DataFrame{Err: fmt.Errorf("boom")}
+		assert.Equal(t, "DataFrame error: boom", df.Print(PrintOptions{MaxRows: 1}))
+	})
+}
+
+func TestDataFrame_JoinWithOptions(t *testing.T) {
+	left := New(
+		series.New([]int{1, 2, 3}, series.Int, "id"),
+		series.New([]string{"a", "b", "c"}, series.String, "name"),
+	)
+	right := New(
+		series.New([]int{1, 2, 3}, series.Int, "id"),
+		series.New([]float64{1.5, 2.5, 3.5}, series.Float, "score"),
+	)
+	dupRight := New(
+		series.New([]int{1, 2, 2}, series.Int, "id"),
+		series.New([]float64{1.5, 2.5, 3.5}, series.Float, "score"),
+	)
+
+	t.Run("passing 1:1", func(t *testing.T) {
+		joined := left.JoinWithOptions(right, "inner", []string{"id"}, JoinValidate("1:1"))
+		assert.NoError(t, joined.Err)
+		assert.Equal(t, left.InnerJoin(right, "id").Records(), joined.Records())
+	})
+
+	t.Run("failing 1:1 with duplicate right keys", func(t *testing.T) {
+		joined := left.JoinWithOptions(dupRight, "left", []string{"id"}, JoinValidate("1:1"))
+		assert.Error(t, joined.Err)
+		assert.Contains(t, joined.Err.Error(), "right")
+	})
+
+	t.Run("1:m allows duplicate right keys", func(t *testing.T) {
+		joined := left.JoinWithOptions(dupRight, "outer", []string{"id"}, JoinValidate("1:m"))
+		assert.NoError(t, joined.Err)
+		assert.Equal(t, 4, joined.Nrow())
+	})
+
+	t.Run("m:1 checks right side", func(t *testing.T) {
+		assert.Error(t, left.JoinWithOptions(dupRight, "right", []string{"id"}, JoinValidate("m:1")).Err)
+		assert.NoError(t, dupRight.JoinWithOptions(left, "right", []string{"id"}, JoinValidate("m:1")).Err)
+	})
+
+	t.Run("unique keys holding the key separator", func(t *testing.T) {
+		a := New(
+			series.New([]string{"a_b", "a"}, series.String, "site"),
+			series.New([]string{"c", "b_c"}, series.String, "device"),
+		)
+		joined := a.JoinWithOptions(a, "inner", []string{"site", "device"}, JoinValidate("1:1"))
+		assert.NoError(t, joined.Err)
+		assert.Equal(t, 2, joined.Nrow())
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		assert.Error(t, left.JoinWithOptions(right, "inner", []string{"id"}, JoinValidate("1:x")).Err)
+		assert.Error(t, left.JoinWithOptions(right, "inner", nil, JoinValidate("1:1")).Err)
+		assert.Error(t, left.JoinWithOptions(right, "inner", []string{"missing"}, JoinValidate("1:1")).Err)
+		assert.Error(t, left.JoinWithOptions(right, "sideways", []string{"id"}).Err)
+	})
+}
+
+func TestUnstackStack(t *testing.T) {
+	long := New(
+		series.New([]string{"r1", "r1", "r2", "r2", "r3"}, series.String, "host"),
+		series.New([]string{"cpu", "mem", "cpu", "mem", "cpu"}, series.String, "variable"),
+		series.New([]float64{0.5, 0.25, 0.75, 0.125, 1}, series.Float, "value"),
+	)
+
+	wide := long.Unstack("host", "variable", "value")
+	assert.NoError(t, wide.Err)
+	assert.Equal(t, [][]string{
+		{"host", "cpu", "mem"},
+		{"r1", "0.500000", "0.250000"},
+		{"r2", "0.750000", "0.125000"},
+		{"r3", "1.000000", "NaN"},
+	}, wide.Records())
+
+	back := wide.Stack([]string{"host"})
+	assert.NoError(t, back.Err)
+	assert.Equal(t, []string{"host", "variable", "value"}, back.Names())
+	assert.Equal(t, []series.Type{series.String, series.String, series.Float}, back.Types())
+	// The missing r3/mem combination comes back as an NA row.
+	expected := append(long.Records(), []string{"r3", "mem", "NaN"})
+	assert.Equal(t, expected, back.Records())
+
+	t.Run("numeric columns keep a numeric value column", func(t *testing.T) {
+		mixed := New(
+			series.New([]string{"r1", "r2"}, series.String, "host"),
+			series.New([]int{3, 4}, series.Int, "flaps"),
+			series.New([]float64{0.5, 1.25}, series.Float, "load"),
+		)
+		stacked := mixed.Stack([]string{"host"})
+		assert.NoError(t, stacked.Err)
+		assert.Equal(t, series.Float, stacked.Col("value").Type())
+		assert.Equal(t, []float64{3, 0.5, 4, 1.25}, stacked.Col("value").Float())
+
+		round := stacked.Unstack("host", "variable", "value")
+		assert.NoError(t, round.Err)
+		assert.Equal(t, []series.Type{series.String, series.Float, series.Float}, round.Types())
+		assert.Equal(t, []float64{3, 4}, round.Col("flaps").Float())
+
+		withString := mixed.Mutate(series.New([]string{"a", "b"}, series.String, "site"))
+		assert.Equal(t, series.String, withString.Stack([]string{"host"}).Col("value").Type())
+	})
+
+	t.Run("duplicated entry", func(t *testing.T) {
+		dup := long.Concat(long.Subset([]int{0}))
+		assert.Error(t, dup.Unstack("host", "variable", "value").Err)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		assert.Error(t, long.Unstack("host", "missing", "value").Err)
+		assert.Error(t, wide.Stack([]string{"missing"}).Err)
+	})
+}
+
+func TestLoadRecordsWithTypes(t *testing.T) {
+	records := [][]string{
+		{"zip", "count", "ratio"},
+		{"01234", "3", "0.5"},
+		{"98765", "x", "1.5"},
+		{"00042", "7.0", "2"},
+	}
+
+	df := LoadRecordsWithTypes(records, map[string]series.Type{
+		"zip":   series.String,
+		"count": series.Int,
+	})
+	assert.NoError(t, df.Err)
+	assert.Equal(t, []series.Type{series.String, series.Int, series.Float}, df.Types())
+	assert.Equal(t, []string{"01234", "98765", "00042"}, df.Col("zip").Records())
+	assert.Equal(t, []string{"3", "NaN", "NaN"}, df.Col("count").Records())
+
+	t.Run("detected types without overrides", func(t *testing.T) {
+		df := LoadRecordsWithTypes(records, nil)
+		assert.NoError(t, df.Err)
+		assert.Equal(t, series.Int, df.Col("zip").Type())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		df := LoadRecordsWithTypes(records, map[string]series.Type{"missing": series.Int})
+		assert.Error(t, df.Err)
+	})
+}
+
+func TestRunLengthEncode(t *testing.T) {
+	df := New(
+		series.New([]string{"up", "up", "down", "up"}, series.String, "status"),
+		series.New([]int{1, 2, 3, 4}, series.Int, "ts"),
+	)
+
+	result := df.RunLengthEncode("status")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, [][]string{
+		{"value", "start", "length"},
+		{"up", "0", "2"},
+		{"down", "2", "1"},
+		{"up", "3", "1"},
+	}, result.Records())
+
+	t.Run("numeric column keeps its type", func(t *testing.T) {
+		result := df.RunLengthEncode("ts")
+		assert.NoError(t, result.Err)
+		assert.Equal(t, series.Int, result.Col("value").Type())
+		assert.Equal(t, 4, result.Nrow())
+	})
+
+	t.Run("empty frame", func(t *testing.T) {
+		empty := New(series.New([]string{}, series.String, "status"))
+		result := empty.RunLengthEncode("status")
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 0, result.Nrow())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		assert.Error(t, df.RunLengthEncode("missing").Err)
+	})
+}
+
+func TestColReductions(t *testing.T) {
+	df := New(
+		series.New([]int{1, 2, 3, 4}, series.Int, "A"),
+		series.New([]float64{0.5, 1.5, 2.5, 3.5}, series.Float, "B"),
+		series.New([]string{"a", "b", "c", "d"}, series.String, "C"),
+		series.New([]bool{true, false, true, true}, series.Bool, "D"),
+	)
+
+	assert.Equal(t, map[string]float64{"A": 10, "B": 8}, df.ColSums())
+	assert.Equal(t, map[string]float64{"A": 2.5, "B": 2}, df.ColMeans())
+	assert.Equal(t, map[string]float64{"A": 1, "B": 0.5}, df.ColMins())
+	assert.Equal(t, map[string]float64{"A": 4, "B": 3.5}, df.ColMaxs())
+
+	errDF := DataFrame{Err: fmt.Errorf("boom")}
+	assert.Empty(t, errDF.ColSums())
+}
+
+func TestNLargestBy(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "c", "d", "e"}, series.String, "host"),
+		series.New([]interface{}{30, nil, 50, 10, 50}, series.Int, "traffic"),
+		series.New([]float64{0.1, 0.2, 0.3, 0.4, 0.5}, series.Float, "ratio"),
+	)
+
+	result := df.NLargestBy(3, "traffic")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, [][]string{
+		{"host", "traffic", "ratio"},
+		{"c", "50", "0.300000"},
+		{"e", "50", "0.500000"},
+		{"a", "30", "0.100000"},
+	}, result.Records())
+
+	t.Run("NA rows sink to the bottom", func(t *testing.T) {
+		result := df.NLargestBy(10, "traffic")
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"c", "e", "a", "d", "b"}, result.Col("host").Records())
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		assert.Error(t, df.NLargestBy(2, "missing").Err)
+		assert.Error(t, df.NLargestBy(-1, "traffic").Err)
+	})
+}
+
+func TestSelectByType(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b"}, series.String, "name"),
+		series.New([]float64{0.5, 1.5}, series.Float, "ratio"),
+		series.New([]bool{true, false}, series.Bool, "up"),
+		series.New([]int{1, 2}, series.Int, "count"),
+	)
+
+	result := df.SelectByType(series.Int, series.Float)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"ratio", "count"}, result.Names())
+	assert.Equal(t, []series.Type{series.Float, series.Int}, result.Types())
+
+	assert.Equal(t, []string{"up"}, df.SelectByType(series.Bool).Names())
+	assert.Error(t, df.Select("name").SelectByType(series.Int).Err)
+}
+
+func TestHash(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b"}, series.String, "name"),
+		series.New([]interface{}{1, nil}, series.Int, "count"),
+	)
+	assert.Equal(t, df.Hash(), df.Copy().Hash())
+
+	changed := df.Copy()
+	changed.Elem(0, 1).Set(2)
+	assert.NotEqual(t, df.Hash(), changed.Hash())
+
+	swapped := df.Select([]string{"count", "name"})
+	assert.NotEqual(t, df.Hash(), swapped.Hash())
+	assert.Equal(t, df.Hash(HashOrderIndependent(true)), swapped.Hash(HashOrderIndependent(true)))
+}
+
+func TestAssertNoNA(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "c"}, series.String, "host"),
+		series.New([]interface{}{1, nil, nil}, series.Int, "port"),
+	)
+
+	assert.NoError(t, df.AssertNoNA("host"))
+
+	err := df.AssertNoNA()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "port (2)")
+	assert.NotContains(t, err.Error(), "host")
+
+	assert.Error(t, df.AssertNoNA("port"))
+	assert.Error(t, df.AssertNoNA("missing"))
+}
+
+func TestFilterNA(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "c"}, series.String, "host"),
+		series.New([]interface{}{1, nil, 3}, series.Int, "port"),
+	)
+	result := df.Filter(F{Colname: "port", Comparator: series.Less, Comparando: 5})
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"a", "c"}, result.Col("host").Records())
+
+	result = df.Filter(
+		F{Colname: "port", Comparator: series.Eq, Comparando: 1},
+		F{Colname: "host", Comparator: series.Eq, Comparando: "b"},
+	)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"a", "b"}, result.Col("host").Records())
+}
+
+func TestReadCSVChunked(t *testing.T) {
+	csvStr := `host,port,load
+a,80,0.5
+b,443,1
+c,8080,1.5
+d,NA,2
+e,22,3
+`
+	var chunks []DataFrame
+	err := ReadCSVChunked(strings.NewReader(csvStr), 2, func(df DataFrame) error {
+		chunks = append(chunks, df)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(chunks))
+
+	total := 0
+	for _, chunk := range chunks {
+		assert.NoError(t, chunk.Err)
+		assert.Equal(t, []string{"host", "port", "load"}, chunk.Names())
+		// "3" alone would be detected as Int without the schema of the first chunk
+		assert.Equal(t, []series.Type{series.String, series.Int, series.Float}, chunk.Types())
+		total += chunk.Nrow()
+	}
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []string{"8080", "NaN"}, chunks[1].Col("port").Records())
+	assert.Equal(t, []string{"3.000000"}, chunks[2].Col("load").Records())
+
+	t.Run("callback error stops reading", func(t *testing.T) {
+		calls := 0
+		err := ReadCSVChunked(strings.NewReader(csvStr), 2, func(df DataFrame) error {
+			calls++
+			return fmt.Errorf("boom")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		assert.Error(t, ReadCSVChunked(strings.NewReader(csvStr), 0, func(DataFrame) error { return nil }))
+	})
+}
+
+func TestOneHotEncode(t *testing.T) {
+	df := New(
+		series.New([]int{1, 2, 3, 4, 5}, series.Int, "id"),
+		series.New([]interface{}{"tcp", "udp", "icmp", nil, "tcp"}, series.String, "proto"),
+	)
+
+	result := df.OneHotEncode("proto")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, [][]string{
+		{"id", "proto", "proto_icmp", "proto_tcp", "proto_udp"},
+		{"1", "tcp", "0", "1", "0"},
+		{"2", "udp", "0", "0", "1"},
+		{"3", "icmp", "1", "0", "0"},
+		{"4", "NaN", "0", "0", "0"},
+		{"5", "tcp", "0", "1", "0"},
+	}, result.Records())
+
+	t.Run("drop first and original", func(t *testing.T) {
+		result := df.OneHotEncode("proto", OHEDropFirst(true), OHEDropOriginal(true))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"id", "proto_tcp", "proto_udp"}, result.Names())
+		assert.Equal(t, series.Int, result.Col("proto_tcp").Type())
+	})
+
+	t.Run("bool indicators", func(t *testing.T) {
+		result := df.OneHotEncode("proto", OHEAsBool(true))
+		assert.Equal(t, []string{"true", "false", "false", "false", "true"}, result.Col("proto_tcp").Records())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		assert.Error(t, df.OneHotEncode("missing").Err)
+	})
+}
+
+func TestAsOfJoin(t *testing.T) {
+	left := New(
+		series.New([]int{1, 5, 10, 12}, series.Int, "ts"),
+		series.New([]float64{0.1, 0.5, 1.0, 1.2}, series.Float, "cpu"),
+	)
+	right := New(
+		series.New([]int{2, 5, 9}, series.Int, "ts"),
+		series.New([]string{"a", "b", "c"}, series.String, "event"),
+	)
+
+	result := AsOfJoin(left, right, "ts", "backward")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"ts", "cpu", "event"}, result.Names())
+	assert.Equal(t, []string{"NaN", "b", "c", "c"}, result.Col("event").Records())
+
+	forward := AsOfJoin(left, right, "ts", "forward")
+	assert.NoError(t, forward.Err)
+	assert.Equal(t, []string{"a", "b", "NaN", "NaN"}, forward.Col("event").Records())
+
+	nearest := AsOfJoin(left, right, "ts", "nearest")
+	assert.NoError(t, nearest.Err)
+	assert.Equal(t, []string{"a", "b", "c", "c"}, nearest.Col("event").Records())
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		assert.Error(t, AsOfJoin(left, right, "missing", "backward").Err)
+		assert.Error(t, AsOfJoin(left, right, "ts", "sideways").Err)
+		unsorted := right.Arrange(RevSort("ts"))
+		assert.Error(t, AsOfJoin(left, unsorted, "ts", "backward").Err)
+	})
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	df := New(
+		series.New([]interface{}{"r1", nil, "r3"}, series.String, "router"),
+		series.New([]interface{}{443, 80, nil}, series.Int, "port"),
+		series.New([]interface{}{nil, 0.25, 1.5}, series.Float, "load"),
+		series.New([]interface{}{true, nil, false}, series.Bool, "up"),
+	)
+
+	var buf bytes.Buffer
+	assert.NoError(t, df.WriteParquet(&buf))
+
+	result := ReadParquet(&buf)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, df.Names(), result.Names())
+	assert.Equal(t, df.Types(), result.Types())
+	assert.Equal(t, df.Records(), result.Records())
+
+	t.Run("invalid input", func(t *testing.T) {
+		assert.Error(t, ReadParquet(strings.NewReader("not parquet")).Err)
+	})
+}
+
+func TestMutateLengthMismatch(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "c"}, series.String, "host"),
+		series.New([]int{1, 2, 3}, series.Int, "port"),
+	)
+
+	short := df.Mutate(series.New([]int{1, 2}, series.Int, "short"))
+	assert.Error(t, short.Err)
+	assert.Contains(t, short.Err.Error(), `series "short" has 2 elements but the dataframe has 3 rows`)
+
+	long := df.Mutate(series.New([]int{1, 2, 3, 4}, series.Int, "port"))
+	assert.Error(t, long.Err)
+	assert.Contains(t, long.Err.Error(), "has 4 elements")
+
+	t.Run("empty frame adopts the series length", func(t *testing.T) {
+		result := DataFrame{}.Mutate(series.New([]int{1, 2}, series.Int, "id"))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 2, result.Nrow())
+		assert.Equal(t, []string{"id"}, result.Names())
+	})
+}
+
+func TestValidateRanges(t *testing.T) {
+	df := New(
+		series.New([]interface{}{10.5, 99.9, nil, 0}, series.Float, "cpu_pct"),
+		series.New([]int{20, 150, 80, -5}, series.Int, "mem_pct"),
+		series.New([]string{"a", "b", "c", "d"}, series.String, "host"),
+	)
+
+	assert.NoError(t, df.ValidateRanges(map[string][2]float64{"cpu_pct": {0, 100}}))
+
+	err := df.ValidateRanges(map[string][2]float64{
+		"cpu_pct": {0, 100},
+		"mem_pct": {0, 100},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "mem_pct: 2 values outside [0, 100] (rows 1, 3)")
+	assert.NotContains(t, err.Error(), "cpu_pct")
+
+	assert.Error(t, df.ValidateRanges(map[string][2]float64{"host": {0, 1}}))
+	assert.Error(t, df.ValidateRanges(map[string][2]float64{"missing": {0, 1}}))
+}
+
+func TestBind(t *testing.T) {
+	a := New(
+		series.New([]string{"r1", "r2"}, series.String, "host"),
+		series.New([]int{1, 2}, series.Int, "flaps"),
+	)
+	b := New(
+		series.New([]float64{0.5, 0.75}, series.Float, "load"),
+		series.New([]int{10, 20}, series.Int, "flaps"),
+	)
+	c := New(series.New([]bool{true, false}, series.Bool, "up"))
+
+	result := Bind([]DataFrame{a, b, c})
+	assert.NoError(t, result.Err)
+	assert.Equal(t, [][]string{
+		{"host", "flaps", "load", "flaps_right", "up"},
+		{"r1", "1", "0.500000", "10", "true"},
+		{"r2", "2", "0.750000", "20", "false"},
+	}, result.Records())
+
+	suffixed := a.Bind(b, WithLeftSuffix("_a"), WithRightSuffix("_b"))
+	assert.Equal(t, []string{"host", "flaps_a", "load", "flaps_b"}, suffixed.Names())
+
+	repeated := Bind([]DataFrame{a, b, a}, WithLeftSuffix("_l"))
+	assert.NoError(t, repeated.Err)
+	assert.Equal(t, []string{"host_l", "flaps_l", "load", "flaps_right", "host_right", "flaps_right2"}, repeated.Names())
+	assert.Equal(t, []string{"1", "2"}, repeated.Col("flaps_right2").Records())
+
+	t.Run("row count mismatch", func(t *testing.T) {
+		short := New(series.New([]int{1}, series.Int, "x"))
+		result := a.Bind(short)
+		assert.Error(t, result.Err)
+		assert.Contains(t, result.Err.Error(), "dimensions mismatch")
+	})
+}
+
+func TestDuplicatedMask(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2", "r1", "r3", "r1", "r2"}, series.String, "host"),
+		series.New([]int{80, 22, 80, 80, 443, 22}, series.Int, "port"),
+	)
+
+	first := df.DuplicatedMask("first")
+	assert.NoError(t, first.Err)
+	assert.Equal(t, series.Bool, first.Type())
+	assert.Equal(t, []string{"false", "false", "true", "false", "false", "true"}, first.Records())
+
+	last := df.DuplicatedMask("last")
+	assert.Equal(t, []string{"true", "true", "false", "false", "false", "false"}, last.Records())
+
+	byHost := df.DuplicatedMask("first", "host")
+	assert.Equal(t, []string{"false", "false", "true", "false", "true", "true"}, byHost.Records())
+
+	// the mask can be used to drop the duplicates
+	assert.Equal(t, 4, df.Subset(df.DuplicatedMask("first").Compare(series.Eq, false)).Nrow())
+
+	assert.Error(t, df.DuplicatedMask("middle").Err)
+	assert.Error(t, df.DuplicatedMask("first", "missing").Err)
+}
+
+func TestApplyMap(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "core", ""}, series.String, "host"),
+		series.New([]interface{}{1, nil, 3}, series.Int, "port"),
+		series.New([]float64{0.5, 1.5, 2.5}, series.Float, "load"),
+	)
+	upper := func(col string, e series.Element) interface{} {
+		if e.Type() == series.String && !e.IsNA() {
+			return strings.ToUpper(e.String())
+		}
+		return e.Val()
+	}
+
+	result := df.ApplyMap(upper)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, df.Types(), result.Types())
+	assert.Equal(t, [][]string{
+		{"host", "port", "load"},
+		{"R1", "1", "0.500000"},
+		{"CORE", "NaN", "1.500000"},
+		{"", "3", "2.500000"},
+	}, result.Records())
+	// the original frame is untouched
+	assert.Equal(t, "r1", df.Elem(0, 0).String())
+
+	t.Run("infer types", func(t *testing.T) {
+		lengths := func(col string, e series.Element) interface{} {
+			if col == "host" {
+				return len(e.String())
+			}
+			return e.Val()
+		}
+		result := df.ApplyMap(lengths, ApplyMapInferTypes(true))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []series.Type{series.Int, series.Int, series.Float}, result.Types())
+		assert.Equal(t, []string{"2", "4", "0"}, result.Col("host").Records())
+		assert.True(t, result.Elem(1, 1).IsNA())
+	})
+}
+
+func TestInferTypes(t *testing.T) {
+	df := LoadRecords(
+		[][]string{
+			{"host", "port", "load", "up", "mixed"},
+			{"r1", "22", "0.5", "true", "1"},
+			{"r2", "NaN", "1", "false", "true"},
+			{"r3", "443", "2.25", "NaN", "x"},
+		},
+		DetectTypes(false),
+	)
+	assert.Equal(t, []series.Type{series.String, series.String, series.String, series.String, series.String}, df.Types())
+
+	result := df.InferTypes()
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []series.Type{series.String, series.Int, series.Float, series.Bool, series.String}, result.Types())
+	assert.True(t, result.Col("port").Elem(1).IsNA())
+	assert.True(t, result.Col("up").Elem(2).IsNA())
+	assert.Equal(t, []float64{0.5, 1, 2.25}, result.Col("load").Float())
+
+	// The same rules as type detection on load apply.
+	records := [][]string{
+		{"flag", "port"},
+		{"1", "22"},
+		{"true", "NaN"},
+	}
+	loaded := LoadRecords(records)
+	inferred := LoadRecords(records, DetectTypes(false)).InferTypes()
+	assert.Equal(t, loaded.Types(), inferred.Types())
+	assert.Equal(t, loaded.Records(), inferred.Records())
+
+	only := df.InferTypes(InferColumns("port"))
+	assert.Equal(t, []series.Type{series.String, series.Int, series.String, series.String, series.String}, only.Types())
+
+	assert.Error(t, df.InferTypes(InferColumns("missing")).Err)
+}
+
+func TestCumAgg(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r1", "r2", "r2"}, series.String, "host"),
+		series.New([]interface{}{10, 5, nil, 20}, series.Int, "bytes"),
+		series.New([]float64{1.5, 0.5, 3, 2}, series.Float, "load"),
+	)
+
+	sums := df.CumAgg(Aggregation_SUM, "bytes", "load")
+	assert.NoError(t, sums.Err)
+	assert.Equal(t, []string{"host", "bytes", "load", "bytes_cumSUM", "load_cumSUM"}, sums.Names())
+	assert.Equal(t, []string{"10.000000", "15.000000", "NaN", "35.000000"}, sums.Col("bytes_cumSUM").Records())
+	assert.Equal(t, []float64{1.5, 2, 5, 7}, sums.Col("load_cumSUM").Float())
+
+	maxs := sums.CumAgg(Aggregation_MAX, "load")
+	assert.Equal(t, []float64{1.5, 1.5, 3, 3}, maxs.Col("load_cumMAX").Float())
+
+	assert.Equal(t, []float64{1.5, 0.75, 2.25, 4.5}, df.CumAgg(Aggregation_PROD, "load").Col("load_cumPROD").Float())
+	assert.Equal(t, []float64{1.5, 0.5, 0.5, 0.5}, df.CumAgg(Aggregation_MIN, "load").Col("load_cumMIN").Float())
+
+	assert.Error(t, df.CumAgg(Aggregation_MEAN, "load").Err)
+	assert.Error(t, df.CumAgg(Aggregation_SUM, "host").Err)
+	assert.Error(t, df.CumAgg(Aggregation_SUM, "missing").Err)
+}
+
+func TestRenameFunc(t *testing.T) {
+	df := New(
+		series.New([]string{"r1"}, series.String, "address.host"),
+		series.New([]int{22}, series.Int, "address.port"),
+		series.New([]string{"up"}, series.String, "Status"),
+	)
+
+	result := df.RenameFunc(func(name string) string {
+		return strings.ToLower(strings.TrimPrefix(name, "address."))
+	})
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"host", "port", "status"}, result.Names())
+	assert.Equal(t, []string{"address.host", "address.port", "Status"}, df.Names())
+
+	collision := df.RenameFunc(func(name string) string {
+		return strings.Split(name, ".")[0]
+	})
+	assert.Error(t, collision.Err)
+	assert.Contains(t, collision.Err.Error(), `"address"`)
+}
+
+func TestLatestBy(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "a", "c", "b", "a"}, series.String, "id"),
+		series.New([]int{1, 1, 3, 1, 2, 2}, series.Int, "version"),
+		series.New([]string{"a1", "b1", "a3", "c1", "b2", "a2"}, series.String, "payload"),
+	)
+
+	result := df.LatestBy([]string{"id"}, "version")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, [][]string{
+		{"id", "version", "payload"},
+		{"a", "3", "a3"},
+		{"b", "2", "b2"},
+		{"c", "1", "c1"},
+	}, result.Records())
+
+	assert.Error(t, df.LatestBy([]string{"id"}, "missing").Err)
+	assert.Error(t, df.LatestBy([]string{"missing"}, "version").Err)
+	assert.Error(t, df.LatestBy(nil, "version").Err)
+}
+
+func TestHTML(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "<r2>", "r3"}, series.String, "host"),
+		series.New([]interface{}{22, nil, 443}, series.Int, "port"),
+	)
+
+	out := df.HTML()
+	assert.True(t, strings.HasPrefix(out, "<table"))
+	assert.Contains(t, out, "<th>host</th><th>port</th>")
+	assert.Contains(t, out, "<th>&lt;string&gt;</th><th>&lt;int&gt;</th>")
+	assert.Contains(t, out, "<td>&lt;r2&gt;</td><td>NaN</td>")
+	// header, types and one row per record
+	assert.Equal(t, 5, strings.Count(out, "<tr>"))
+
+	empty := df.HTML(HTMLEmptyNA(true))
+	assert.Contains(t, empty, "<td>&lt;r2&gt;</td><td></td>")
+
+	short := df.HTML(HTMLMaxRows(1))
+	assert.Equal(t, 4, strings.Count(short, "<tr>"))
+	assert.Contains(t, short, "<td>...</td>")
+}
+
+func TestJoinOn(t *testing.T) {
+	inventory := New(
+		series.New([]string{"Core-1", "edge-2", "EDGE-3"}, series.String, "host"),
+		series.New([]string{"dc1", "dc2", "dc2"}, series.String, "site"),
+	)
+	metrics := New(
+		series.New([]string{"core-1", "Edge-2", "edge-4"}, series.String, "host"),
+		series.New([]float64{0.5, 0.75, 0.25}, series.Float, "load"),
+	)
+	lower := JoinKey{Column: "host", Transform: func(e series.Element) string {
+		return strings.ToLower(e.String())
+	}}
+
+	inner := inventory.JoinOn(metrics, "inner", lower)
+	assert.NoError(t, inner.Err)
+	assert.Equal(t, [][]string{
+		{"host_0", "site", "host_1", "load"},
+		{"Core-1", "dc1", "core-1", "0.500000"},
+		{"edge-2", "dc2", "Edge-2", "0.750000"},
+	}, inner.Records())
+
+	left := inventory.JoinOn(metrics, "left", lower)
+	assert.NoError(t, left.Err)
+	assert.Equal(t, 3, left.Nrow())
+
+	raw := inventory.JoinOn(metrics, "inner", JoinKey{Column: "host"})
+	assert.Equal(t, 0, raw.Nrow())
+
+	assert.Error(t, inventory.JoinOn(metrics, "sideways", lower).Err)
+	assert.Error(t, inventory.JoinOn(metrics, "inner", JoinKey{Column: "missing"}).Err)
+	assert.Error(t, inventory.JoinOn(metrics, "inner").Err)
+}
+
+func TestGroupAggregateNAKeys(t *testing.T) {
+	df := New(
+		series.New([]interface{}{"core", nil, "edge", "core", nil}, series.String, "role"),
+		series.New([]interface{}{1, 2, 3, nil, 5}, series.Int, "tier"),
+		series.New([]float64{1, 2, 3, 4, 5}, series.Float, "load"),
+	)
+	aggOn := AggreateOn([]AggregationType{Aggregation_SUM}, []string{"load"})
+
+	dropped := GroupAggregate(df, GroupOn("role"), aggOn)
+	assert.NoError(t, dropped.Err)
+	assert.Equal(t, [][]string{
+		{"role", "load_SUM"},
+		{"core", "5.000000"},
+		{"edge", "3.000000"},
+	}, dropped.Records())
+
+	kept := GroupAggregate(df, GroupOn("role"), aggOn, WithDropNAKeys(false))
+	assert.NoError(t, kept.Err)
+	assert.Equal(t, [][]string{
+		{"role", "load_SUM"},
+		{"core", "5.000000"},
+		{"edge", "3.000000"},
+		{"NaN", "7.000000"},
+	}, kept.Records())
+	assert.True(t, kept.Col("role").Elem(2).IsNA())
+
+	byTier := GroupAggregate(df, GroupOn("tier"), aggOn, WithDropNAKeys(false))
+	assert.NoError(t, byTier.Err)
+	assert.Equal(t, []string{"1", "2", "3", "5", "NaN"}, byTier.Col("tier").Records())
+
+	// the last option wins
+	both := GroupAggregate(df, GroupOn("role", "tier"), aggOn, WithDropNAKeys(false), WithDropNAKeys(true))
+	assert.Equal(t, [][]string{
+		{"role", "tier", "load_SUM"},
+		{"core", "1", "1.000000"},
+		{"edge", "3", "3.000000"},
+	}, both.Records())
+
+	// caller-defined GroupOptions are applied to the result as before
+	var onlySums GroupOption = func(df DataFrame) DataFrame {
+		return df.Select([]string{"load_SUM"})
+	}
+	sums := GroupAggregate(df, GroupOn("role"), aggOn, WithDropNAKeys(false), onlySums)
+	assert.Equal(t, []string{"5.000000", "3.000000", "7.000000"}, sums.Col("load_SUM").Records())
+}
+
+func TestCorrWith(t *testing.T) {
+	df := New(
+		series.New([]float64{1, 2, 3, 4, 5}, series.Float, "traffic"),
+		series.New([]string{"a", "b", "c", "d", "e"}, series.String, "host"),
+		series.New([]float64{12, 14, 16, 18, 20}, series.Float, "power"),
+		series.New([]int{3, 1, 4, 1, 5}, series.Int, "noise"),
+		series.New([]interface{}{nil, 1, nil, nil, nil}, series.Int, "sparse"),
+	)
+
+	corr := df.CorrWith("traffic")
+	assert.NoError(t, corr.Err)
+	assert.Equal(t, "traffic", corr.Name)
+	assert.Equal(t, 3, corr.Len())
+	assert.InDelta(t, 1.0, corr.Elem(0).Float(), 1e-9)
+	assert.True(t, math.Abs(corr.Elem(1).Float()) < 0.5)
+	assert.True(t, corr.Elem(2).IsNA())
+
+	assert.Error(t, df.CorrWith("host").Err)
+	assert.Error(t, df.CorrWith("missing").Err)
+}
+
+func TestToCSVString(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r,2"}, series.String, "host"),
+		series.New([]interface{}{22, nil}, series.Int, "port"),
+	)
+
+	assert.Equal(t, "host,port\nr1,22\n\"r,2\",NaN\n", df.ToCSVString())
+	assert.Equal(t, "r1,22\n\"r,2\",\n", df.ToCSVString(WriteHeader(false), WriteNAValue("")))
+	assert.Equal(t, "", DataFrame{Err: fmt.Errorf("boom")}.ToCSVString())
+}
+
+func TestEqualApprox(t *testing.T) {
+	a := New(
+		series.New([]string{"r1", "r2"}, series.String, "host"),
+		series.New([]float64{0.5, 1.0 / 3}, series.Float, "load"),
+	)
+	b := New(
+		series.New([]string{"r1", "r2"}, series.String, "host"),
+		series.New([]float64{0.5 + 1e-9, 1.0/3 - 1e-9}, series.Float, "load"),
+	)
+
+	assert.False(t, a.Equal(b))
+	assert.True(t, a.EqualApprox(b, 1e-6))
+	assert.False(t, a.EqualApprox(b, 1e-12))
+
+	renamed := b.Rename("cpu", "load")
+	assert.False(t, a.EqualApprox(renamed, 1e-6))
+	assert.False(t, a.EqualApprox(b.Subset([]int{0}), 1e-6))
+}
+
+func TestAssertUnique(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r1", "r2", "r1"}, series.String, "host"),
+		series.New([]int{80, 443, 80, 80}, series.Int, "port"),
+		series.New([]int{1, 2, 3, 4}, series.Int, "id"),
+	)
+
+	assert.NoError(t, df.AssertUnique("id"))
+	assert.NoError(t, df.AssertUnique())
+
+	err := df.AssertUnique("host", "port")
+	assert.Error(t, err)
+	assert.Equal(t, "assert unique: duplicated keys on host, port: (r1, 80) at rows 0, 3", err.Error())
+
+	err = df.AssertUnique("host")
+	assert.Contains(t, err.Error(), "(r1) at rows 0, 1, 3")
+	assert.Error(t, df.AssertUnique("missing"))
+}
+
+func TestLoadRecordsNumberFormat(t *testing.T) {
+	records := [][]string{
+		{"site", "traffic", "cost"},
+		{"Paris, FR", "1.234.567", "1.234,56"},
+		{"Oslo", "980", "7,5"},
+	}
+
+	df := LoadRecords(records, WithNumberFormat(series.EUNumberFormat))
+	assert.NoError(t, df.Err)
+	assert.Equal(t, []series.Type{series.String, series.Int, series.Float}, df.Types())
+	assert.Equal(t, "Paris, FR", df.Elem(0, 0).String())
+	assert.Equal(t, []float64{1234567, 980}, df.Col("traffic").Float())
+	assert.Equal(t, []float64{1234.56, 7.5}, df.Col("cost").Float())
+
+	us := LoadRecords(
+		[][]string{{"cost"}, {"1,234.56"}, {"12"}},
+		WithNumberFormat(series.USNumberFormat),
+		WithTypes(map[string]series.Type{"cost": series.Float}),
+	)
+	assert.Equal(t, []float64{1234.56, 12}, us.Col("cost").Float())
+
+	plain := LoadRecords(records)
+	assert.Equal(t, []series.Type{series.String, series.String, series.String}, plain.Types())
+}
+
+func TestReindex(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2"}, series.String, "host"),
+		series.New([]int{22, 80}, series.Int, "port"),
+		series.New([]bool{true, false}, series.Bool, "up"),
+	)
+
+	result := df.Reindex([]string{"port", "site", "host", "load"}, ReindexTypes(map[string]series.Type{"load": series.Float}))
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []string{"port", "site", "host", "load"}, result.Names())
+	assert.Equal(t, []series.Type{series.Int, series.String, series.String, series.Float}, result.Types())
+	assert.Equal(t, [][]string{
+		{"port", "site", "host", "load"},
+		{"22", "NaN", "r1", "NaN"},
+		{"80", "NaN", "r2", "NaN"},
+	}, result.Records())
+	assert.True(t, result.Col("site").Elem(0).IsNA())
+
+	// frames with different schemas can then be concatenated
+	other := New(series.New([]string{"r3"}, series.String, "host"))
+	all := result.Concat(other.Reindex(result.Names(), ReindexTypes(map[string]series.Type{"port": series.Int, "load": series.Float})))
+	assert.NoError(t, all.Err)
+	assert.Equal(t, 3, all.Nrow())
+
+	assert.Error(t, df.Reindex([]string{"host", "host"}).Err)
+	assert.Error(t, df.Reindex(nil).Err)
+}
+
+func TestSetIndex(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2", "r3", "r2"}, series.String, "host"),
+		series.New([]int{22, 80, 443, 8080}, series.Int, "port"),
+	)
+	assert.Equal(t, "", df.Index())
+
+	indexed := df.SetIndex("host")
+	assert.NoError(t, indexed.Err)
+	assert.Equal(t, "host", indexed.Index())
+	assert.Equal(t, df.Records(), indexed.Records())
+
+	assert.Equal(t, [][]string{{"host", "port"}, {"r3", "443"}}, indexed.Loc("r3").Records())
+	assert.Equal(t, []string{"80", "8080"}, indexed.Loc("r2").Col("port").Records())
+	assert.Error(t, indexed.Loc("r9").Err)
+
+	byPort := df.SetIndex("port")
+	assert.Equal(t, "r3", byPort.Loc(443).Elem(0, 0).String())
+	assert.Equal(t, "r3", byPort.Loc("443").Elem(0, 0).String())
+
+	reset := indexed.ResetIndex()
+	assert.Equal(t, "", reset.Index())
+	assert.Equal(t, df.Names(), reset.Names())
+	assert.Error(t, reset.Loc("r1").Err)
+
+	assert.Error(t, df.SetIndex("missing").Err)
+}
+
+func TestSetIndex_AfterMutation(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "c"}, series.String, "host"),
+		series.New([]int{22, 80, 443}, series.Int, "port"),
+	).SetIndex("host")
+
+	copied := df.Copy()
+	assert.Equal(t, "host", copied.Index())
+
+	df = df.Set([]int{0}, New(
+		series.New([]string{"z"}, series.String, "host"),
+		series.New([]int{8080}, series.Int, "port"),
+	))
+	assert.NoError(t, df.Err)
+	assert.Error(t, df.Loc("a").Err)
+	assert.Equal(t, [][]string{{"host", "port"}, {"z", "8080"}}, df.Loc("z").Records())
+	v, err := df.At("z", "port")
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, v)
+
+	// The copy keeps the values and the index from before Set.
+	assert.Equal(t, "22", copied.Loc("a").Col("port").Records()[0])
+	assert.Error(t, copied.Loc("z").Err)
+
+	renamed := df.Rename("name", "host")
+	assert.Equal(t, "name", renamed.Index())
+	assert.Equal(t, "8080", renamed.Loc("z").Col("port").Records()[0])
+}
+
+func TestAt(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2"}, series.String, "host"),
+		series.New([]interface{}{22, nil}, series.Int, "port"),
+	).SetIndex("host")
+
+	v, err := df.At("r1", "port")
+	assert.NoError(t, err)
+	assert.Equal(t, 22, v)
+
+	v, err = df.At("r2", "port")
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	_, err = df.At("r9", "port")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "r9")
+
+	_, err = df.At("r1", "missing")
+	assert.Error(t, err)
+
+	_, err = df.ResetIndex().At("r1", "port")
+	assert.Error(t, err)
+}
+
+func TestReduce(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2", "r3"}, series.String, "host"),
+		series.New([]float64{2, 4, 6}, series.Float, "load"),
+		series.New([]int{10, 10, 10}, series.Int, "flaps"),
+	)
+	cv := func(s series.Series) float64 {
+		return s.StdDev() / s.Mean()
+	}
+
+	result := df.Reduce(cv)
+	assert.Equal(t, 3, len(result))
+	assert.InDelta(t, 0.5, result["load"], 1e-9)
+	assert.Equal(t, 0.0, result["flaps"])
+	assert.True(t, math.IsNaN(result["host"]))
+
+	assert.Equal(t, 0, len(DataFrame{Err: fmt.Errorf("boom")}.Reduce(cv)))
+}
+
+func TestConcatWithOptions(t *testing.T) {
+	a := New(
+		series.New([]string{"r1"}, series.String, "host"),
+		series.New([]int{22}, series.Int, "port"),
+	)
+	b := New(
+		series.New([]int{80}, series.Int, "port"),
+		series.New([]float64{0.5}, series.Float, "load"),
+	)
+	broken := DataFrame{Err: fmt.Errorf("bad import")}
+
+	sorted := ConcatWithOptions([]DataFrame{a, b}, WithSortColumns(true))
+	assert.NoError(t, sorted.Err)
+	assert.Equal(t, []string{"host", "load", "port"}, sorted.Names())
+	assert.Equal(t, []string{"22", "80"}, sorted.Col("port").Records())
+
+	failed := Concat(a, broken, b)
+	assert.Error(t, failed.Err)
+	assert.Equal(t, "concat: input 1: bad import", failed.Err.Error())
+
+	skipped := ConcatWithOptions([]DataFrame{broken, a, broken, b}, WithResetErr())
+	assert.NoError(t, skipped.Err)
+	assert.Equal(t, 2, skipped.Nrow())
+
+	assert.Error(t, ConcatWithOptions([]DataFrame{broken}, WithResetErr()).Err)
+}
+
+func TestDropNARows(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2", "r3", "r4"}, series.String, "host"),
+		series.New([]interface{}{22, nil, 443, 80}, series.Int, "port"),
+		series.New([]interface{}{0.5, 0.1, nil, 0.2}, series.Float, "load"),
+	)
+
+	assert.Equal(t, []string{"r1", "r3", "r4"}, df.DropNARows("port").Col("host").Records())
+	assert.Equal(t, []string{"r1", "r4"}, df.DropNARows().Col("host").Records())
+	assert.Equal(t, []string{"r2", "r3"}, df.KeepNARows("port", "load").Col("host").Records())
+	assert.Equal(t, []string{"r2"}, df.KeepNARows("port").Col("host").Records())
+	assert.Equal(t, 4, df.DropNARows("host").Nrow())
+
+	assert.Error(t, df.DropNARows("missing").Err)
+	assert.Error(t, df.KeepNARows("missing").Err)
+}
+
+func TestDataFrame_DescribeCategorical(t *testing.T) {
+	df := New(
+		series.New([]interface{}{"edge", "core", "edge", nil, "core", "edge"}, series.String, "role"),
+		series.New([]int{1, 2, 3, 4, 5, 6}, series.Int, "vlan"),
+		series.New([]bool{true, false, false, true, false, true}, series.Bool, "up"),
+	)
+	result := df.DescribeCategorical()
+	assert.NoError(t, result.Err)
+	assert.Equal(t, [][]string{
+		{"column", "role", "up"},
+		{"count", "5", "6"},
+		{"unique", "2", "2"},
+		{"top", "edge", "true"},
+		{"freq", "3", "3"},
+	}, result.Records())
+}
+
+func TestDataFrame_SampleWeighted(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "b", "c", "d"}, series.String, "flow"),
+		series.New([]float64{1, 1, 100, 0}, series.Float, "bytes"),
+	)
+
+	t.Run("With replacement", func(t *testing.T) {
+		result := df.SampleWeighted(2000, "bytes", 42, true)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 2000, result.Nrow())
+		counts := map[string]int{}
+		for _, flow := range result.Col("flow").Records() {
+			counts[flow]++
+		}
+		assert.True(t, counts["c"] > 10*(counts["a"]+counts["b"]), fmt.Sprint(counts))
+		assert.Equal(t, 0, counts["d"])
+
+		again := df.SampleWeighted(2000, "bytes", 42, true)
+		assert.Equal(t, result.Records(), again.Records())
+	})
+
+	t.Run("Without replacement", func(t *testing.T) {
+		first := 0
+		for seed := int64(0); seed < 200; seed++ {
+			result := df.SampleWeighted(3, "bytes", seed, false)
+			assert.NoError(t, result.Err)
+			flows := result.Col("flow").Records()
+			assert.Equal(t, 3, len(flows))
+			assert.False(t, flows[0] == flows[1] || flows[1] == flows[2] || flows[0] == flows[2])
+			if flows[0] == "c" {
+				first++
+			}
+		}
+		assert.True(t, first > 180, fmt.Sprint(first))
+		assert.Error(t, df.SampleWeighted(4, "bytes", 1, false).Err)
+	})
+
+	t.Run("Invalid weights", func(t *testing.T) {
+		bad := New(
+			series.New([]string{"a", "b", "c"}, series.String, "flow"),
+			series.New([]interface{}{-1.0, nil, 5.0}, series.Float, "bytes"),
+		)
+		assert.Error(t, bad.SampleWeighted(1, "bytes", 1, true).Err)
+		result := bad.SampleWeighted(5, "bytes", 1, true, SampleZeroInvalid(true))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"c", "c", "c", "c", "c"}, result.Col("flow").Records())
+		assert.Error(t, df.SampleWeighted(1, "flow", 1, true).Err)
+		assert.Error(t, df.SampleWeighted(1, "missing", 1, true).Err)
+	})
+}
@@ -0,0 +1,171 @@
+package dataframe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/netxops/frame/series"
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetColumnsKey is the key of the file metadata entry holding the column
+// order, since Parquet groups sort their fields by name.
+const parquetColumnsKey = "dataframe.columns"
+
+// WriteParquet writes the DataFrame to the given io.Writer as a Parquet file.
+// Every column is written as an optional field, String as UTF-8 byte arrays,
+// Int as INT64, Float as DOUBLE and Bool as BOOLEAN, with NA elements stored as
+// nulls through the definition levels.
+func (df DataFrame) WriteParquet(w io.Writer) error {
+	if df.Err != nil {
+		return df.Err
+	}
+	group := parquet.Group{}
+	for _, col := range df.columns {
+		var node parquet.Node
+		switch col.Type() {
+		case series.String:
+			node = parquet.String()
+		case series.Int:
+			node = parquet.Int(64)
+		case series.Float:
+			node = parquet.Leaf(parquet.DoubleType)
+		case series.Bool:
+			node = parquet.Leaf(parquet.BooleanType)
+		default:
+			return fmt.Errorf("write parquet: unsupported type %s on column %s", col.Type(), col.Name)
+		}
+		group[col.Name] = parquet.Optional(node)
+	}
+	schema := parquet.NewSchema("dataframe", group)
+
+	// Leaf columns follow the sorted field order of the schema.
+	leaves := make([]series.Series, 0, df.ncols)
+	for _, field := range schema.Fields() {
+		leaves = append(leaves, df.columns[df.colIndex(field.Name())])
+	}
+	order, err := json.Marshal(df.Names())
+	if err != nil {
+		return err
+	}
+
+	writer := parquet.NewWriter(w, schema, parquet.KeyValueMetadata(parquetColumnsKey, string(order)))
+	rows := make([]parquet.Row, df.nrows)
+	for i := range rows {
+		row := make(parquet.Row, len(leaves))
+		for j, col := range leaves {
+			e := col.Elem(i)
+			if e.IsNA() {
+				row[j] = parquet.NullValue().Level(0, 0, j)
+				continue
+			}
+			var v parquet.Value
+			switch col.Type() {
+			case series.String:
+				v = parquet.ValueOf(e.String())
+			case series.Int:
+				n, _ := e.Int()
+				v = parquet.ValueOf(int64(n))
+			case series.Float:
+				v = parquet.ValueOf(e.Float())
+			case series.Bool:
+				b, _ := e.Bool()
+				v = parquet.ValueOf(b)
+			}
+			row[j] = v.Level(0, 1, j)
+		}
+		rows[i] = row
+	}
+	if _, err := writer.WriteRows(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// ReadParquet reads a Parquet file from a io.Reader and builds a DataFrame with
+// its columns. BOOLEAN fields become Bool columns, integer fields Int, floating
+// point fields Float and byte array fields String; null values become NA.
+// Files written by WriteParquet keep their original column order, others list
+// their columns in schema order.
+func ReadParquet(r io.Reader) DataFrame {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return DataFrame{Err: err}
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("read parquet: %v", err)}
+	}
+
+	fields := file.Schema().Fields()
+	types := make([]series.Type, len(fields))
+	for j, field := range fields {
+		if !field.Leaf() {
+			return DataFrame{Err: fmt.Errorf("read parquet: nested field %s is not supported", field.Name())}
+		}
+		switch field.Type().Kind() {
+		case parquet.Boolean:
+			types[j] = series.Bool
+		case parquet.Int32, parquet.Int64:
+			types[j] = series.Int
+		case parquet.Float, parquet.Double:
+			types[j] = series.Float
+		case parquet.ByteArray, parquet.FixedLenByteArray:
+			types[j] = series.String
+		default:
+			return DataFrame{Err: fmt.Errorf("read parquet: unsupported kind %s on field %s", field.Type().Kind(), field.Name())}
+		}
+	}
+
+	nrows := int(file.NumRows())
+	cells := make([][]interface{}, len(fields))
+	for j := range cells {
+		cells[j] = make([]interface{}, nrows)
+	}
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	rows := make([]parquet.Row, 1)
+	for i := 0; i < nrows; i++ {
+		if _, err := reader.ReadRows(rows); err != nil && !(err == io.EOF && i == nrows-1) {
+			return DataFrame{Err: fmt.Errorf("read parquet: %v", err)}
+		}
+		for _, v := range rows[0] {
+			j := v.Column()
+			if v.IsNull() {
+				continue
+			}
+			switch v.Kind() {
+			case parquet.Boolean:
+				cells[j][i] = v.Boolean()
+			case parquet.Int32:
+				cells[j][i] = int(v.Int32())
+			case parquet.Int64:
+				cells[j][i] = int(v.Int64())
+			case parquet.Float:
+				cells[j][i] = float64(v.Float())
+			case parquet.Double:
+				cells[j][i] = v.Double()
+			default:
+				cells[j][i] = string(v.ByteArray())
+			}
+		}
+	}
+
+	columns := make([]series.Series, len(fields))
+	for j, field := range fields {
+		columns[j] = series.New(cells[j], types[j], field.Name())
+	}
+	df := New(columns...)
+	if df.Err != nil {
+		return df
+	}
+	if value, ok := file.Lookup(parquetColumnsKey); ok {
+		var names []string
+		if err := json.Unmarshal([]byte(value), &names); err == nil && len(names) == df.ncols {
+			df = df.Select(names)
+		}
+	}
+	return df
+}
@@ -0,0 +1,132 @@
+package dataframe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/netxops/frame/series"
+)
+
+// GroupedRollingWindow is used for rolling window calculations that restart at
+// every group boundary, so values never bleed across unrelated partitions.
+type GroupedRollingWindow struct {
+	window int
+	df     DataFrame
+	groups [][]int
+
+	Err error
+}
+
+// RollingByGroup creates a new GroupedRollingWindow. Rows are partitioned by the
+// values of the `by` columns and the window is applied within each partition in
+// row order.
+func (df DataFrame) RollingByGroup(by []string, window int) GroupedRollingWindow {
+	r := GroupedRollingWindow{window: window, df: df}
+	if df.Err != nil {
+		r.Err = df.Err
+		return r
+	}
+	_, groups, err := df.groupRowIndexes(by...)
+	if err != nil {
+		r.Err = fmt.Errorf("rolling by group: %v", err)
+		return r
+	}
+	r.groups = groups
+	return r
+}
+
+// Mean returns the rolling mean of the given column, computed per group and
+// aligned with the rows of the original DataFrame.
+func (r GroupedRollingWindow) Mean(colname string) series.Series {
+	return r.apply(colname, "Mean", series.RollingWindow.Mean)
+}
+
+// StdDev returns the rolling standard deviation of the given column, computed
+// per group and aligned with the rows of the original DataFrame.
+func (r GroupedRollingWindow) StdDev(colname string) series.Series {
+	return r.apply(colname, "StdDev", series.RollingWindow.StdDev)
+}
+
+func (r GroupedRollingWindow) apply(colname, suffix string, f func(series.RollingWindow) series.Series) series.Series {
+	name := colname + "_" + suffix
+	if r.Err != nil {
+		return series.Series{Name: name, Err: r.Err}
+	}
+	col := r.df.Col(colname)
+	if col.Err != nil {
+		return series.Series{Name: name, Err: fmt.Errorf("rolling by group: %v", col.Err)}
+	}
+	values := make([]float64, r.df.nrows)
+	for _, idx := range r.groups {
+		rolled := f(col.Subset(idx).Rolling(r.window))
+		for k, i := range idx {
+			values[i] = rolled.Elem(k).Float()
+		}
+	}
+	return series.New(values, series.Float, name)
+}
+
+// groupRowIndexes partitions the rows of the DataFrame by the values of the
+// given columns. It returns the group keys in order of first appearance and, for
+// each of them, the indexes of the rows belonging to the group.
+//
+// Rows are partitioned on the exact tuple of values, NA being distinct from
+// any value and floats compared on all their digits, while the returned keys
+// join the values with "_" for display. Distinct tuples rendering to the same
+// key, e.g. ("a_b", "c") and ("a", "b_c"), or floats equal up to the six
+// decimals shown, are told apart by suffixing the later key with "#n".
+func (df DataFrame) groupRowIndexes(colnames ...string) ([]string, [][]int, error) {
+	if len(colnames) == 0 {
+		return nil, nil, fmt.Errorf("no group columns given")
+	}
+	cols := make([]series.Series, len(colnames))
+	for i, c := range colnames {
+		idx := df.colIndex(c)
+		if idx < 0 {
+			return nil, nil, fmt.Errorf("can't find column name: %s", c)
+		}
+		cols[i] = df.columns[idx]
+	}
+
+	var keys []string
+	var groups [][]int
+	position := make(map[string]int)
+	taken := make(map[string]bool)
+	parts := make([]string, len(cols))
+	tuple := make([]string, len(cols))
+	for i := 0; i < df.nrows; i++ {
+		for j, col := range cols {
+			e := col.Elem(i)
+			parts[j] = e.String()
+			switch {
+			case e.IsNA():
+				tuple[j] = "NA"
+			case col.Type() == series.Float:
+				f := e.Float()
+				if f == 0 {
+					f = 0 // -0 and 0 are the same value
+				}
+				tuple[j] = strconv.FormatFloat(f, 'g', -1, 64)
+			default:
+				tuple[j] = strconv.Quote(parts[j])
+			}
+		}
+		exact := strings.Join(tuple, ",")
+		p, ok := position[exact]
+		if !ok {
+			key := strings.Join(parts, "_")
+			label := key
+			for n := 1; taken[label]; n++ {
+				label = fmt.Sprintf("%s#%d", key, n)
+			}
+			taken[label] = true
+			p = len(keys)
+			position[exact] = p
+			keys = append(keys, label)
+			groups = append(groups, nil)
+		}
+		groups[p] = append(groups[p], i)
+	}
+	return keys, groups, nil
+}
@@ -0,0 +1,100 @@
+package dataframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/netxops/frame/series"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataFrame_RollingByGroup(t *testing.T) {
+	df := New(
+		series.New([]string{"a", "a", "a", "b", "b", "b", "b"}, series.String, "device"),
+		series.New([]int{1, 2, 3, 10, 20, 30, 40}, series.Int, "value"),
+	)
+
+	t.Run("Mean restarts at group boundaries", func(t *testing.T) {
+		result := df.RollingByGroup([]string{"device"}, 2).Mean("value")
+		assert.NoError(t, result.Err)
+		assert.Equal(t, "value_Mean", result.Name)
+
+		expected := []float64{math.NaN(), 1.5, 2.5, math.NaN(), 15, 25, 35}
+		received := result.Float()
+		for i := range expected {
+			if math.IsNaN(expected[i]) {
+				assert.True(t, math.IsNaN(received[i]), "row %d should be NaN", i)
+				continue
+			}
+			assert.InDelta(t, expected[i], received[i], 1e-9, "row %d", i)
+		}
+	})
+
+	t.Run("Interleaved groups", func(t *testing.T) {
+		interleaved := New(
+			series.New([]string{"a", "b", "a", "b"}, series.String, "device"),
+			series.New([]float64{1, 10, 3, 30}, series.Float, "value"),
+		)
+		result := interleaved.RollingByGroup([]string{"device"}, 2).Mean("value")
+		received := result.Float()
+		assert.True(t, math.IsNaN(received[0]))
+		assert.True(t, math.IsNaN(received[1]))
+		assert.InDelta(t, 2.0, received[2], 1e-9)
+		assert.InDelta(t, 20.0, received[3], 1e-9)
+	})
+
+	t.Run("Multiple group columns with separators in values", func(t *testing.T) {
+		ambiguous := New(
+			series.New([]string{"a_b", "a", "a_b", "a"}, series.String, "site"),
+			series.New([]string{"c", "b_c", "c", "b_c"}, series.String, "device"),
+			series.New([]float64{1, 10, 3, 30}, series.Float, "value"),
+		)
+		result := ambiguous.RollingByGroup([]string{"site", "device"}, 2).Mean("value")
+		received := result.Float()
+		assert.True(t, math.IsNaN(received[0]))
+		assert.True(t, math.IsNaN(received[1]))
+		assert.InDelta(t, 2.0, received[2], 1e-9)
+		assert.InDelta(t, 20.0, received[3], 1e-9)
+
+		keys, groups, err := ambiguous.groupRowIndexes("site", "device")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a_b_c", "a_b_c#1"}, keys)
+		assert.Equal(t, [][]int{{0, 2}, {1, 3}}, groups)
+	})
+
+	t.Run("NA keys form their own group", func(t *testing.T) {
+		withNA := New(
+			series.New([]interface{}{"NA", nil, "NA", nil}, series.String, "device"),
+			series.New([]float64{1, 10, 3, 30}, series.Float, "value"),
+		)
+		_, groups, err := withNA.groupRowIndexes("device")
+		assert.NoError(t, err)
+		assert.Equal(t, [][]int{{0, 2}, {1, 3}}, groups)
+	})
+
+	t.Run("Near-equal floats stay apart", func(t *testing.T) {
+		near := New(
+			series.New([]float64{0.1234561, 0.1234562, 0.1234561, 0, math.Copysign(0, -1)}, series.Float, "ratio"),
+		)
+		keys, groups, err := near.groupRowIndexes("ratio")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0.123456", "0.123456#1", "0.000000"}, keys)
+		assert.Equal(t, [][]int{{0, 2}, {1}, {3, 4}}, groups)
+
+		assert.NoError(t, near.Subset([]int{0, 1}).AssertUnique("ratio"))
+		mask, err := near.DuplicatedMask("first").Bool()
+		assert.NoError(t, err)
+		assert.Equal(t, []bool{false, false, true, false, true}, mask)
+		assert.Equal(t, 3, GroupCount(near, GroupOn("ratio")).Nrow())
+	})
+
+	t.Run("Unknown group column", func(t *testing.T) {
+		result := df.RollingByGroup([]string{"missing"}, 2).Mean("value")
+		assert.Error(t, result.Err)
+	})
+
+	t.Run("Unknown value column", func(t *testing.T) {
+		result := df.RollingByGroup([]string{"device"}, 2).StdDev("missing")
+		assert.Error(t, result.Err)
+	})
+}
@@ -0,0 +1,302 @@
+package dataframe
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netxops/frame/series"
+)
+
+// SQLOption is the type used to configure WriteSQL
+type SQLOption func(*sqlOptions)
+
+type sqlOptions struct {
+	// batchSize is the maximum number of rows sent on every INSERT statement
+	batchSize int
+
+	// columnMap maps DataFrame column names to table column names. Columns
+	// not present on the map keep their name.
+	columnMap map[string]string
+
+	// placeholder renders the n-th parameter of a statement, counting from 1
+	placeholder func(n int) string
+
+	// quote renders a table or column name as a quoted identifier
+	quote func(name string) string
+}
+
+// SQLBatchSize sets the batchSize option for sqlOptions.
+func SQLBatchSize(n int) SQLOption {
+	return func(o *sqlOptions) {
+		o.batchSize = n
+	}
+}
+
+// SQLColumnMap sets the columnMap option for sqlOptions.
+func SQLColumnMap(m map[string]string) SQLOption {
+	return func(o *sqlOptions) {
+		o.columnMap = m
+	}
+}
+
+// SQLPlaceholder sets the placeholder option for sqlOptions. It defaults to
+// "?"; drivers using numbered parameters may pass, for instance,
+// func(n int) string { return fmt.Sprintf("$%d", n) }.
+func SQLPlaceholder(f func(n int) string) SQLOption {
+	return func(o *sqlOptions) {
+		o.placeholder = f
+	}
+}
+
+// SQLQuoteIdentifier sets the quote option for sqlOptions. It defaults to
+// quoting names between double quotes, as standard SQL does; MySQL without
+// ANSI_QUOTES may pass, for instance, a function quoting with backticks.
+func SQLQuoteIdentifier(f func(name string) string) SQLOption {
+	return func(o *sqlOptions) {
+		o.quote = f
+	}
+}
+
+// quoteIdentifier quotes a name between double quotes, doubling the double
+// quotes it holds.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// WriteSQL inserts the rows of the DataFrame into an existing table, sending
+// up to SQLBatchSize rows (500 by default) on every INSERT statement. String
+// columns are passed as string parameters, Int as int64, Float as float64 and
+// Bool as bool, with NA elements passed as SQL NULL. All the batches run in a
+// single transaction, so either every row is inserted or none is. The table
+// and column names are quoted with SQLQuoteIdentifier; the table name may be
+// qualified with a schema, as in "public.devices", and each part is quoted on
+// its own.
+func (df DataFrame) WriteSQL(db *sql.DB, table string, options ...SQLOption) error {
+	if df.Err != nil {
+		return df.Err
+	}
+	cfg := sqlOptions{
+		batchSize:   500,
+		placeholder: func(int) string { return "?" },
+		quote:       quoteIdentifier,
+	}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if cfg.batchSize <= 0 {
+		return fmt.Errorf("write sql: batch size must be positive, got %d", cfg.batchSize)
+	}
+	if df.nrows == 0 {
+		return nil
+	}
+
+	if table == "" {
+		return fmt.Errorf("write sql: empty table name")
+	}
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = cfg.quote(part)
+	}
+	names := make([]string, df.ncols)
+	for j, col := range df.columns {
+		name := col.Name
+		if mapped, ok := cfg.columnMap[col.Name]; ok {
+			name = mapped
+		}
+		names[j] = cfg.quote(name)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", strings.Join(parts, "."), strings.Join(names, ", "))
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("write sql: %v", err)
+	}
+	for start := 0; start < df.nrows; start += cfg.batchSize {
+		end := start + cfg.batchSize
+		if end > df.nrows {
+			end = df.nrows
+		}
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*df.ncols)
+		for i := start; i < end; i++ {
+			if i > start {
+				query.WriteString(", ")
+			}
+			query.WriteString("(")
+			for j, col := range df.columns {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				args = append(args, sqlValue(col.Elem(i), col.Type()))
+				query.WriteString(cfg.placeholder(len(args)))
+			}
+			query.WriteString(")")
+		}
+		if _, err := tx.Exec(query.String(), args...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("write sql: rows %d to %d: %v", start, end-1, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("write sql: %v", err)
+	}
+	return nil
+}
+
+// sqlValue returns the statement parameter for the given element.
+func sqlValue(e series.Element, t series.Type) interface{} {
+	if e.IsNA() {
+		return nil
+	}
+	switch t {
+	case series.Int:
+		n, _ := e.Int()
+		return int64(n)
+	case series.Float:
+		return e.Float()
+	case series.Bool:
+		b, _ := e.Bool()
+		return b
+	default:
+		return e.String()
+	}
+}
+
+// ReadSQL reads all the rows of a query result into a DataFrame, closing them
+// when done. Column types follow the database type names reported by the
+// driver, see sqlTypes: integer types become Int columns, floating point and
+// decimal types Float, boolean types Bool and anything else String. Columns without a type
+// name take the type of their first non-NULL value. NULL values become NA.
+func ReadSQL(rows *sql.Rows) DataFrame {
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("read sql: %v", err)}
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return DataFrame{Err: fmt.Errorf("read sql: %v", err)}
+	}
+
+	cells := make([][]interface{}, len(names))
+	for j := range cells {
+		cells[j] = []interface{}{}
+	}
+	dest := make([]interface{}, len(names))
+	ptrs := make([]interface{}, len(names))
+	for j := range dest {
+		ptrs[j] = &dest[j]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return DataFrame{Err: fmt.Errorf("read sql: %v", err)}
+		}
+		for j, v := range dest {
+			switch x := v.(type) {
+			case []byte:
+				v = string(x)
+			case int64:
+				v = int(x)
+			case int32:
+				v = int(x)
+			case float32:
+				v = float64(x)
+			case time.Time:
+				v = x.Format(time.RFC3339Nano)
+			}
+			cells[j] = append(cells[j], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return DataFrame{Err: fmt.Errorf("read sql: %v", err)}
+	}
+
+	columns := make([]series.Series, len(names))
+	for j, name := range names {
+		t, ok := sqlColumnType(colTypes[j].DatabaseTypeName())
+		if !ok {
+			t = series.String
+			for _, v := range cells[j] {
+				if v != nil {
+					t = sqlValueType(v)
+					break
+				}
+			}
+		}
+		for i, v := range cells[j] {
+			switch x := v.(type) {
+			case int:
+				if t == series.Bool {
+					cells[j][i] = x != 0
+				} else if t == series.Float {
+					cells[j][i] = float64(x)
+				}
+			}
+		}
+		columns[j] = series.New(cells[j], t, name)
+	}
+	return New(columns...)
+}
+
+// sqlTypes maps database type names, without their parameters, to series
+// types. Names not listed become String columns.
+var sqlTypes = map[string]series.Type{
+	"BOOL":             series.Bool,
+	"BOOLEAN":          series.Bool,
+	"INT":              series.Int,
+	"INTEGER":          series.Int,
+	"BIGINT":           series.Int,
+	"SMALLINT":         series.Int,
+	"TINYINT":          series.Int,
+	"MEDIUMINT":        series.Int,
+	"INT2":             series.Int,
+	"INT4":             series.Int,
+	"INT8":             series.Int,
+	"UNSIGNED BIG INT": series.Int,
+	"SERIAL":           series.Int,
+	"BIGSERIAL":        series.Int,
+	"SMALLSERIAL":      series.Int,
+	"REAL":             series.Float,
+	"FLOAT":            series.Float,
+	"FLOAT4":           series.Float,
+	"FLOAT8":           series.Float,
+	"DOUBLE":           series.Float,
+	"DOUBLE PRECISION": series.Float,
+	"NUMERIC":          series.Float,
+	"DECIMAL":          series.Float,
+}
+
+// sqlColumnType maps a database type name to a series type, ignoring case,
+// parameters such as in "DECIMAL(10,2)" and a trailing UNSIGNED. It reports
+// false for empty names.
+func sqlColumnType(name string) (series.Type, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return "", false
+	}
+	if i := strings.Index(name, "("); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	name = strings.TrimSpace(strings.TrimSuffix(name, " UNSIGNED"))
+	if t, ok := sqlTypes[name]; ok {
+		return t, true
+	}
+	return series.String, true
+}
+
+// sqlValueType returns the series type matching a scanned value.
+func sqlValueType(v interface{}) series.Type {
+	switch v.(type) {
+	case int:
+		return series.Int
+	case float64:
+		return series.Float
+	case bool:
+		return series.Bool
+	default:
+		return series.String
+	}
+}
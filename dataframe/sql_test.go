@@ -0,0 +1,170 @@
+package dataframe
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/netxops/frame/series"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite returns an in-memory SQLite database holding the given tables.
+// A single connection is kept open, as every connection to ":memory:" opens a
+// database of its own.
+func openSQLite(t *testing.T, schema ...string) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestDataFrame_WriteSQL(t *testing.T) {
+	df := New(
+		series.New([]string{"r1", "r2", "r3"}, series.String, "host"),
+		series.New([]interface{}{80, nil, 443}, series.Int, "port"),
+		series.New([]interface{}{0.5, 1.5, nil}, series.Float, "load"),
+		series.New([]interface{}{true, nil, false}, series.Bool, "up"),
+	)
+	const schema = `CREATE TABLE devices (host TEXT PRIMARY KEY, port INTEGER, "cpu load" REAL, up BOOLEAN)`
+	query := `SELECT host, port, "cpu load", up FROM devices ORDER BY host`
+
+	db := openSQLite(t, schema)
+	defer db.Close()
+
+	err := df.WriteSQL(db, "devices", SQLBatchSize(2), SQLColumnMap(map[string]string{"load": "cpu load"}))
+	assert.NoError(t, err)
+	rows, err := db.Query(query)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"host", "port", "cpu load", "up"},
+		{"r1", "80", "0.500000", "true"},
+		{"r2", "NaN", "1.500000", "NaN"},
+		{"r3", "443", "NaN", "false"},
+	}, ReadSQL(rows).Records())
+
+	t.Run("Quoted names", func(t *testing.T) {
+		db := openSQLite(t, `CREATE TABLE "order" ("select" TEXT, "a""b" INTEGER)`)
+		defer db.Close()
+
+		quoted := New(
+			series.New([]string{"x"}, series.String, "select"),
+			series.New([]int{1}, series.Int, `a"b`),
+		)
+		assert.NoError(t, quoted.WriteSQL(db, "order"))
+		assert.NoError(t, quoted.WriteSQL(db, "main.order"))
+		var n int
+		assert.NoError(t, db.QueryRow(`SELECT count(*) FROM "order" WHERE "a""b" = 1`).Scan(&n))
+		assert.Equal(t, 2, n)
+
+		assert.Error(t, quoted.WriteSQL(db, "order; DROP TABLE order"))
+		assert.Error(t, quoted.WriteSQL(db, ""))
+	})
+
+	t.Run("Numbered placeholders", func(t *testing.T) {
+		db := openSQLite(t, schema)
+		defer db.Close()
+
+		err := df.Select([]string{"host", "port"}).WriteSQL(db, "devices",
+			SQLPlaceholder(func(n int) string { return fmt.Sprintf("?%d", n) }))
+		assert.NoError(t, err)
+		var port sql.NullInt64
+		assert.NoError(t, db.QueryRow("SELECT port FROM devices WHERE host = 'r3'").Scan(&port))
+		assert.Equal(t, sql.NullInt64{Int64: 443, Valid: true}, port)
+	})
+
+	t.Run("Failed batch rolls back", func(t *testing.T) {
+		db := openSQLite(t, schema)
+		defer db.Close()
+
+		dup := df.RBind(df.Subset([]int{0}))
+		assert.Error(t, dup.WriteSQL(db, "devices", SQLBatchSize(1)))
+		var n int
+		assert.NoError(t, db.QueryRow("SELECT count(*) FROM devices").Scan(&n))
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Invalid batch size", func(t *testing.T) {
+		assert.Error(t, df.WriteSQL(db, "devices", SQLBatchSize(0)))
+	})
+}
+
+func TestReadSQL(t *testing.T) {
+	db := openSQLite(t,
+		`CREATE TABLE devices (host VARCHAR(16), port INTEGER, load DOUBLE PRECISION, up BOOLEAN)`,
+		`INSERT INTO devices VALUES ('r1', 80, 0.5, 1), ('r2', NULL, 2, 0), (NULL, 443, NULL, NULL)`,
+	)
+	defer db.Close()
+
+	rows, err := db.Query("SELECT host, port, load, up, port + 1 AS next FROM devices ORDER BY rowid")
+	assert.NoError(t, err)
+	df := ReadSQL(rows)
+	assert.NoError(t, df.Err)
+	assert.Equal(t, []series.Type{series.String, series.Int, series.Float, series.Bool, series.Int}, df.Types())
+	assert.Equal(t, [][]string{
+		{"host", "port", "load", "up", "next"},
+		{"r1", "80", "0.500000", "true", "81"},
+		{"r2", "NaN", "2.000000", "false", "NaN"},
+		{"NaN", "443", "NaN", "NaN", "444"},
+	}, df.Records())
+
+	t.Run("No rows", func(t *testing.T) {
+		rows, err := db.Query("SELECT host FROM devices WHERE 0")
+		assert.NoError(t, err)
+
+		df := ReadSQL(rows)
+		assert.NoError(t, df.Err)
+		assert.Equal(t, []string{"host"}, df.Names())
+		assert.Equal(t, 0, df.Nrow())
+	})
+}
+
+func TestReadSQL_TypeNames(t *testing.T) {
+	db := openSQLite(t,
+		`CREATE TABLE metrics (a INT, b BIGINT UNSIGNED, c SMALLINT, d INT8, e DECIMAL(10,2), f FLOAT8,
+			g INTERVAL, h POINT, i BOOL, j TEXT)`,
+		`INSERT INTO metrics VALUES (1, 2, 3, 4, 5.25, 6.5, '1 day', '(1,2)', 1, 'x'),
+			(NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL)`,
+	)
+	defer db.Close()
+
+	rows, err := db.Query("SELECT * FROM metrics ORDER BY rowid")
+	assert.NoError(t, err)
+	df := ReadSQL(rows)
+	assert.NoError(t, df.Err)
+	assert.Equal(t, []series.Type{
+		series.Int, series.Int, series.Int, series.Int, series.Float, series.Float,
+		series.String, series.String, series.Bool, series.String,
+	}, df.Types())
+	assert.Equal(t, [][]string{
+		{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"},
+		{"1", "2", "3", "4", "5.250000", "6.500000", "1 day", "(1,2)", "true", "x"},
+		{"NaN", "NaN", "NaN", "NaN", "NaN", "NaN", "NaN", "NaN", "NaN", "NaN"},
+	}, df.Records())
+
+	for name, expected := range map[string]series.Type{
+		"integer":          series.Int,
+		"Int4":             series.Int,
+		"INT UNSIGNED":     series.Int,
+		"double precision": series.Float,
+		"NUMERIC(8)":       series.Float,
+		"INTERVAL":         series.String,
+		"POINT":            series.String,
+		"VARCHAR(255)":     series.String,
+		"TIMESTAMP":        series.String,
+	} {
+		typ, ok := sqlColumnType(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, typ, name)
+	}
+	_, ok := sqlColumnType("")
+	assert.False(t, ok)
+}
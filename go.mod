@@ -1,16 +1,31 @@
 module github.com/netxops/frame
 
-go 1.23
+go 1.23.0
 
 require (
 	github.com/jinzhu/copier v0.4.0
+	github.com/parquet-go/parquet-go v0.25.1
 	github.com/spf13/cast v1.7.1
 	github.com/stretchr/testify v1.2.2
 	golang.org/x/net v0.0.0-20210423184538-5f58ad60dda6
 	gonum.org/v1/gonum v0.9.1
+	modernc.org/sqlite v1.38.0
 )
 
 require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/exp v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	modernc.org/libc v1.65.10 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
 )
@@ -1,8 +1,10 @@
 package series_test
 
 import (
+	"math"
 	"math/rand"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/netxops/frame/series"
@@ -256,3 +258,102 @@ func BenchmarkSeries_Set(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkSeries_RollingMedian(b *testing.B) {
+	rand.Seed(100)
+	s := series.Floats(generateFloats(10000))
+	window := 100
+	// naive sorts a copy of every block, as a generic rolling apply would
+	naive := func(s series.Series) series.Series {
+		values := make([]float64, s.Len())
+		for i := range values {
+			if i < window-1 {
+				values[i] = math.NaN()
+				continue
+			}
+			idx := make([]int, window)
+			for j := range idx {
+				idx[j] = i - window + 1 + j
+			}
+			values[i] = s.Subset(idx).Median()
+		}
+		return series.Floats(values)
+	}
+	b.Run("RollingMedian", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.RollingMedian(window)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naive(s)
+		}
+	})
+}
+
+func BenchmarkSeries_OrderTopK(b *testing.B) {
+	rand.Seed(100)
+	s := series.Floats(generateFloats(100000))
+	b.Run("OrderTopK(10)", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.OrderTopK(10, true)
+		}
+	})
+	b.Run("Order", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.Order(true)
+		}
+	})
+}
+
+func BenchmarkSeries_MapParallel(b *testing.B) {
+	rand.Seed(100)
+	s := series.Floats(generateFloats(100000))
+	expensive := func(e series.Element) series.Element {
+		ret := e.Copy()
+		f := e.Float()
+		for i := 0; i < 100; i++ {
+			f = math.Sqrt(f + 1)
+		}
+		ret.Set(f)
+		return ret
+	}
+	b.Run("Map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.Map(expensive)
+		}
+	})
+	b.Run("MapParallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.MapParallel(expensive, 0)
+		}
+	})
+}
+
+func BenchmarkSeries_MapCached(b *testing.B) {
+	statuses := []string{"up", "down", "admin-down", "testing", "unknown"}
+	values := make([]string, 100000)
+	for i := range values {
+		values[i] = statuses[i%len(statuses)]
+	}
+	s := series.Strings(values)
+	expensive := func(e series.Element) series.Element {
+		ret := e.Copy()
+		str := e.String()
+		for i := 0; i < 50; i++ {
+			str = strings.ToUpper(strings.ToLower(str))
+		}
+		ret.Set(str)
+		return ret
+	}
+	b.Run("Map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.Map(expensive)
+		}
+	})
+	b.Run("MapCached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.MapCached(expensive)
+		}
+	})
+}
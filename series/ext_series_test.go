@@ -1,6 +1,9 @@
 package series
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -340,3 +343,991 @@ func TestMin(t *testing.T) {
 		assert.Contains(t, result.Err.Error(), "series of type")
 	})
 }
+
+func TestSeries_ToMap(t *testing.T) {
+	s := New([]interface{}{nil, 2, nil, 4, nil}, Int, "sparse")
+
+	m := s.ToMap()
+	assert.Equal(t, map[int]interface{}{1: 2, 3: 4}, m)
+
+	t.Run("Round trip through SeriesFromMap", func(t *testing.T) {
+		result := SeriesFromMap(m, s.Len(), s.Type(), s.Name)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, s.Records(), result.Records())
+		assert.Equal(t, []bool{true, false, true, false, true}, result.IsNaN())
+	})
+
+	t.Run("String values", func(t *testing.T) {
+		result := SeriesFromMap(map[int]interface{}{0: "a", 2: "c"}, 3, String, "letters")
+		assert.Equal(t, []string{"a", "NaN", "c"}, result.Records())
+		assert.Equal(t, []bool{false, true, false}, result.IsNaN())
+	})
+
+	t.Run("Index out of range", func(t *testing.T) {
+		result := SeriesFromMap(map[int]interface{}{5: 1.0}, 3, Float, "f")
+		assert.Error(t, result.Err)
+	})
+}
+
+func TestSeries_ToColumn(t *testing.T) {
+	tests := []struct {
+		name           string
+		s              Series
+		expectedValues interface{}
+	}{
+		{"String", New([]interface{}{"a", nil, "c"}, String, "s"), []string{"a", "", "c"}},
+		{"Int", New([]interface{}{nil, 2, 3}, Int, "i"), []int{0, 2, 3}},
+		{"Float", New([]interface{}{1.5, 2.5, nil}, Float, "f"), []float64{1.5, 2.5, 0}},
+		{"Bool", New([]interface{}{true, nil, false}, Bool, "b"), []bool{true, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, validity, err := tt.s.ToColumn()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValues, values)
+			assert.Len(t, validity, 1)
+			for i, na := range tt.s.IsNaN() {
+				assert.Equal(t, !na, validity[0]&(1<<uint(i)) != 0, "validity bit %d", i)
+			}
+
+			result := FromColumn(values, validity, tt.s.Name)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.s.Type(), result.Type())
+			assert.Equal(t, tt.s.Name, result.Name)
+			assert.Equal(t, tt.s.Records(), result.Records())
+			assert.Equal(t, tt.s.IsNaN(), result.IsNaN())
+		})
+	}
+
+	t.Run("Bitmap spanning several bytes", func(t *testing.T) {
+		values := make([]interface{}, 10)
+		values[9] = 9
+		_, validity, err := New(values, Int, "").ToColumn()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x00, 0x02}, validity)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, _, err := Series{t: "unknown", elements: intElements{}}.ToColumn()
+		assert.Error(t, err)
+		errored := Ints([]int{1})
+		errored.Err = fmt.Errorf("boom")
+		_, _, err = errored.ToColumn()
+		assert.Error(t, err)
+	})
+
+	t.Run("Nil validity marks all valid", func(t *testing.T) {
+		result := FromColumn([]int{1, 2}, nil, "n")
+		assert.False(t, result.HasNaN())
+	})
+
+	t.Run("Unsupported values", func(t *testing.T) {
+		result := FromColumn([]uint8{1}, nil, "n")
+		assert.Error(t, result.Err)
+	})
+}
+
+func TestSeries_Sort(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        Series
+		reverse  bool
+		expected []string
+	}{
+		{"Int ascending", New([]interface{}{3, nil, 1, 2}, Int, "i"), false, []string{"1", "2", "3", "NaN"}},
+		{"Int descending", New([]interface{}{3, nil, 1, 2}, Int, "i"), true, []string{"3", "2", "1", "NaN"}},
+		{"Float ascending", New([]interface{}{2.5, nil, -1.0, nil}, Float, "f"), false, []string{"-1.000000", "2.500000", "NaN", "NaN"}},
+		{"Float descending", New([]interface{}{2.5, nil, -1.0, 7.0}, Float, "f"), true, []string{"7.000000", "2.500000", "-1.000000", "NaN"}},
+		{"String ascending", New([]interface{}{"b", "c", nil, "a"}, String, "s"), false, []string{"a", "b", "c", "NaN"}},
+		{"String descending", New([]interface{}{"b", "c", nil, "a"}, String, "s"), true, []string{"c", "b", "a", "NaN"}},
+		{"Empty", New([]int{}, Int, "e"), false, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.s.Records()
+			result := tt.s.Sort(tt.reverse)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.s.Name, result.Name)
+			assert.Equal(t, tt.s.Type(), result.Type())
+			assert.Equal(t, tt.expected, result.Records())
+			assert.Equal(t, original, tt.s.Records(), "original series must not be modified")
+		})
+	}
+}
+
+func TestSeries_MaskWhere(t *testing.T) {
+	t.Run("mask above threshold", func(t *testing.T) {
+		s := New([]float64{1, 2, 100, 3, 250}, Float, "readings")
+		masked := s.MaskWhere(s.Compare(Greater, 10.0))
+		assert.NoError(t, masked.Err)
+		assert.Equal(t, []string{"1.000000", "2.000000", "NaN", "3.000000", "NaN"}, masked.Records())
+		assert.Equal(t, 2.0, masked.Mean())
+		assert.Equal(t, "100.000000", s.Elem(2).String(), "original series must not be modified")
+	})
+
+	t.Run("all types", func(t *testing.T) {
+		cond := New([]bool{true, false, true}, Bool, "cond")
+		for _, s := range []Series{
+			New([]string{"a", "b", "c"}, String, "s"),
+			New([]int{1, 2, 3}, Int, "i"),
+			New([]float64{1, 2, 3}, Float, "f"),
+			New([]bool{true, true, false}, Bool, "b"),
+		} {
+			masked := s.MaskWhere(cond)
+			assert.NoError(t, masked.Err)
+			assert.Equal(t, s.Type(), masked.Type())
+			assert.Equal(t, []bool{true, false, true}, masked.IsNaN())
+		}
+	})
+
+	t.Run("NA condition leaves value", func(t *testing.T) {
+		s := New([]int{1, 2}, Int, "i")
+		masked := s.MaskWhere(New([]interface{}{nil, true}, Bool, "cond"))
+		assert.NoError(t, masked.Err)
+		assert.Equal(t, []string{"1", "NaN"}, masked.Records())
+	})
+
+	t.Run("length mismatch", func(t *testing.T) {
+		s := New([]int{1, 2, 3}, Int, "i")
+		assert.Error(t, s.MaskWhere(New([]bool{true}, Bool, "cond")).Err)
+	})
+
+	t.Run("non bool condition", func(t *testing.T) {
+		s := New([]int{1, 2, 3}, Int, "i")
+		assert.Error(t, s.MaskWhere(New([]int{1, 0, 1}, Int, "cond")).Err)
+	})
+}
+
+func TestSeries_FirstLastValid(t *testing.T) {
+	s := New([]interface{}{nil, nil, 3, nil, 5, nil}, Int, "gaps")
+
+	i, e := s.FirstValid()
+	assert.Equal(t, 2, i)
+	assert.Equal(t, 3, e.Val())
+
+	i, e = s.LastValid()
+	assert.Equal(t, 4, i)
+	assert.Equal(t, 5, e.Val())
+
+	for _, empty := range []Series{
+		New([]interface{}{nil, nil}, Float, "na"),
+		New([]string{}, String, "empty"),
+	} {
+		i, e = empty.FirstValid()
+		assert.Equal(t, -1, i)
+		assert.Nil(t, e)
+		i, e = empty.LastValid()
+		assert.Equal(t, -1, i)
+		assert.Nil(t, e)
+	}
+}
+
+func TestSeries_ChangePoints(t *testing.T) {
+	s := New([]interface{}{"up", "up", "down", "down", nil, nil, "up", "down"}, String, "status")
+
+	result := s.ChangePoints()
+	assert.NoError(t, result.Err)
+	assert.Equal(t, Bool, result.Type())
+	assert.Equal(t, "status", result.Name)
+	expected := []bool{false, false, true, false, true, false, true, true}
+	received, err := result.Bool()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, received)
+
+	first, err := s.ChangePoints(WithFirstChange(true)).Bool()
+	assert.NoError(t, err)
+	assert.True(t, first[0])
+	assert.Equal(t, expected[1:], first[1:])
+
+	ints, err := New([]int{1, 1, 2}, Int, "i").ChangePoints().Bool()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false, true}, ints)
+
+	floats, err := New([]float64{0.1234561, 0.1234562, 1e-9, 2e-9, 2e-9}, Float, "f").ChangePoints().Bool()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true, true, true, false}, floats)
+
+	assert.Equal(t, 0, New([]string{}, String, "empty").ChangePoints().Len())
+}
+
+func TestSeries_CumCount(t *testing.T) {
+	s := New([]interface{}{"a", "b", "a", nil, "a", "b", nil, "NaN!"}, String, "keys")
+	result := s.CumCount()
+	assert.NoError(t, result.Err)
+	assert.Equal(t, Int, result.Type())
+	assert.Equal(t, "keys", result.Name)
+	assert.Equal(t, []string{"1", "1", "2", "1", "3", "2", "2", "1"}, result.Records())
+
+	ints := New([]int{5, 5, 5}, Int, "i").CumCount()
+	assert.Equal(t, []string{"1", "2", "3"}, ints.Records())
+
+	floats := New([]float64{0.1234561, 0.1234562, 0.1234561}, Float, "f").CumCount()
+	assert.Equal(t, []string{"1", "1", "2"}, floats.Records())
+}
+
+func TestSeries_Runs(t *testing.T) {
+	s := New([]interface{}{"up", "up", nil, nil, "down", "up", "up", "up"}, String, "state")
+	assert.Equal(t, []Run{
+		{Value: "up", Start: 0, Length: 2},
+		{Value: nil, Start: 2, Length: 2},
+		{Value: "down", Start: 4, Length: 1},
+		{Value: "up", Start: 5, Length: 3},
+	}, s.Runs())
+
+	ints := New([]interface{}{1, 1, nil, 2}, Int, "i")
+	assert.Equal(t, []Run{
+		{Value: 1, Start: 0, Length: 2},
+		{Value: nil, Start: 2, Length: 1},
+		{Value: 2, Start: 3, Length: 1},
+	}, ints.Runs())
+	assert.Equal(t, []Run{}, New([]int{}, Int, "empty").Runs())
+
+	floats := New([]float64{0.1234561, 0.1234562, 0.1234562}, Float, "f")
+	assert.Equal(t, []Run{
+		{Value: 0.1234561, Start: 0, Length: 1},
+		{Value: 0.1234562, Start: 1, Length: 2},
+	}, floats.Runs())
+}
+
+func TestSeries_CumNUnique(t *testing.T) {
+	s := New([]interface{}{"r1", "r2", "r1", nil, "r3", nil, "r2", "NaN!"}, String, "hosts")
+	result := s.CumNUnique()
+	assert.NoError(t, result.Err)
+	assert.Equal(t, Int, result.Type())
+	assert.Equal(t, "hosts", result.Name)
+	assert.Equal(t, []string{"1", "2", "2", "3", "4", "4", "4", "5"}, result.Records())
+
+	ints := New([]int{7, 7, 7}, Int, "i").CumNUnique()
+	assert.Equal(t, []string{"1", "1", "1"}, ints.Records())
+
+	floats := New([]float64{0.1234561, 0.1234562, 1e-9, 2e-9}, Float, "f").CumNUnique()
+	assert.Equal(t, []string{"1", "2", "3", "4"}, floats.Records())
+	assert.Equal(t, 0, New([]int{}, Int, "empty").CumNUnique().Len())
+}
+
+func TestSeries_NLargestNSmallest(t *testing.T) {
+	s := New([]interface{}{4, 9, nil, 1, 9, 4, 7}, Int, "traffic")
+
+	largest := s.NLargest(3)
+	assert.NoError(t, largest.Err)
+	assert.Equal(t, "traffic", largest.Name)
+	assert.Equal(t, []string{"9", "9", "7"}, largest.Records())
+
+	smallest := s.NSmallest(3)
+	assert.NoError(t, smallest.Err)
+	assert.Equal(t, []string{"1", "4", "4"}, smallest.Records())
+
+	// n larger than the number of valid elements returns all of them
+	assert.Equal(t, []string{"9", "9", "7", "4", "4", "1"}, s.NLargest(10).Records())
+	assert.Equal(t, 0, s.NSmallest(0).Len())
+	assert.Equal(t, 0, New([]interface{}{nil, nil}, Int, "na").NLargest(2).Len())
+	assert.Error(t, s.NLargest(-1).Err)
+}
+
+func TestSeries_Winsorize(t *testing.T) {
+	s := New([]interface{}{-100, 2, 3, nil, 4, 5, 6, 7, 8, 9, 1000}, Int, "latency")
+	result := s.Winsorize(0.15, 0.9)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, Int, result.Type())
+	assert.Equal(t, "latency", result.Name)
+	assert.Equal(t, []string{"2", "2", "3", "NaN", "4", "5", "6", "7", "8", "9", "9"}, result.Records())
+	// the receiver is not modified
+	assert.Equal(t, "-100", s.Elem(0).String())
+
+	floats := New([]float64{0.5, 1.5, 2.5, 3.5}, Float, "f").Winsorize(0, 1)
+	assert.Equal(t, []float64{0.5, 1.5, 2.5, 3.5}, floats.Float())
+
+	assert.Error(t, New([]string{"a"}, String, "s").Winsorize(0.1, 0.9).Err)
+	assert.Error(t, s.Winsorize(0.9, 0.1).Err)
+	assert.Error(t, s.Winsorize(-0.1, 0.5).Err)
+	assert.Error(t, s.Winsorize(0.1, 1.5).Err)
+}
+
+func TestSeries_Hash(t *testing.T) {
+	a := New([]interface{}{1.5, nil, 3.25}, Float, "x")
+	b := New([]interface{}{1.5, nil, 3.25}, Float, "x")
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	changed := b.Copy()
+	changed.Elem(2).Set(3.5)
+	assert.NotEqual(t, a.Hash(), changed.Hash())
+
+	renamed := b.Copy()
+	renamed.Name = "y"
+	assert.NotEqual(t, a.Hash(), renamed.Hash())
+
+	// NA differs from any value, including the zero value
+	zero := New([]interface{}{1.5, 0.0, 3.25}, Float, "x")
+	assert.NotEqual(t, a.Hash(), zero.Hash())
+
+	assert.NotEqual(t, New([]int{1}, Int, "x").Hash(), New([]string{"1"}, String, "x").Hash())
+}
+
+func TestSeries_CompareNA(t *testing.T) {
+	s := New([]interface{}{1, nil, 3, 4}, Int, "x")
+
+	mask := s.Compare(Greater, 2)
+	assert.NoError(t, mask.Err)
+	assert.Equal(t, []string{"false", "NaN", "true", "true"}, mask.Records())
+
+	other := New([]interface{}{1, 2, nil, 4}, Int, "y")
+	assert.Equal(t, []string{"true", "NaN", "NaN", "true"}, s.Compare(Eq, other).Records())
+	assert.Equal(t, []string{"true", "NaN", "true", "false"}, s.Compare(In, []int{1, 3}).Records())
+
+	// NA entries of a mask are excluded when subsetting
+	subset := s.Subset(s.Compare(Neq, 4))
+	assert.NoError(t, subset.Err)
+	assert.Equal(t, []string{"1", "3"}, subset.Records())
+}
+
+func TestSeries_Pipe(t *testing.T) {
+	s := New([]interface{}{-100, 2, 3, 4, 5, 6, 7, 8, 9, 1000}, Int, "latency")
+	double := func(s Series) Series { return s.Mul(2, s.Name) }
+	top := func(s Series) Series { return s.NLargest(3) }
+	clip := func(s Series) Series { return s.Winsorize(0.15, 0.9) }
+
+	result := s.Pipe(clip, double, top)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, "latency", result.Name)
+	assert.Equal(t, []string{"18", "18", "16"}, result.Records())
+
+	calls := 0
+	count := func(s Series) Series { calls++; return s }
+	failed := New([]string{"a"}, String, "s").Pipe(count, clip, count)
+	assert.Error(t, failed.Err)
+	assert.Contains(t, failed.Err.Error(), "winsorize")
+	assert.Equal(t, 1, calls)
+
+	assert.True(t, s.Equal(s.Pipe()))
+}
+
+func TestSeries_ReplaceRegexAll(t *testing.T) {
+	s := New([]interface{}{"core  router   01", nil, "edge switch"}, String, "device")
+	result := s.ReplaceRegexAll(`\s+`, " ")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, "device", result.Name)
+	assert.Equal(t, []string{"core router 01", "NaN", "edge switch"}, result.Records())
+
+	ids := New([]string{"if-eth0", "if-eth1", "lo"}, String, "ifname")
+	assert.Equal(t, []string{"eth0", "eth1", "lo"}, ids.ReplaceRegexAll(`^if-`, "").Records())
+
+	assert.Error(t, s.ReplaceRegexAll(`(`, "").Err)
+	assert.Error(t, New([]int{1}, Int, "i").ReplaceRegexAll(`1`, "2").Err)
+}
+
+func TestSeries_SearchSorted(t *testing.T) {
+	s := New([]int{10, 20, 20, 30}, Int, "ts")
+
+	left, err := s.SearchSorted([]int{5, 10, 20, 25, 30, 35}, "left")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 1, 3, 3, 4}, left)
+
+	right, err := s.SearchSorted([]int{5, 10, 20, 25, 30, 35}, "right")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 3, 3, 4, 4}, right)
+
+	floats := New([]interface{}{0.5, 1.5, nil}, Float, "f")
+	idx, err := floats.SearchSorted([]interface{}{1.0, 2.0, nil}, "left")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 2}, idx)
+	idx, err = floats.SearchSorted([]interface{}{2.0, nil}, "right")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, idx)
+
+	strs := New([]string{"a", "c", "e"}, String, "s")
+	idx, err = strs.SearchSorted("d", "left")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, idx)
+
+	_, err = s.SearchSorted(10, "middle")
+	assert.Error(t, err)
+
+	unsorted := New([]int{3, 1, 2}, Int, "u")
+	_, err = unsorted.SearchSorted(2, "left")
+	assert.NoError(t, err)
+	_, err = unsorted.SearchSorted(2, "left", WithCheckSorted(true))
+	assert.Error(t, err)
+}
+
+func TestSeries_Autocorr(t *testing.T) {
+	values := make([]interface{}, 48)
+	for i := range values {
+		values[i] = math.Sin(2 * math.Pi * float64(i) / 12)
+	}
+	values[5] = nil
+	s := New(values, Float, "daily")
+
+	assert.InDelta(t, 1.0, s.Autocorr(12), 1e-9)
+	assert.InDelta(t, -1.0, s.Autocorr(6), 1e-9)
+	assert.True(t, math.Abs(s.Autocorr(3)) < 0.2)
+
+	assert.True(t, math.IsNaN(New([]string{"a", "b"}, String, "s").Autocorr(1)))
+	assert.True(t, math.IsNaN(New([]int{1, 2}, Int, "i").Autocorr(5)))
+}
+
+func TestSeries_OrderTopK(t *testing.T) {
+	s := New([]interface{}{5, 1, nil, 9, 5, 3, 9, nil, 1}, Int, "x")
+	for _, reverse := range []bool{false, true} {
+		full := s.Order(reverse)
+		for k := 0; k <= s.Len(); k++ {
+			assert.Equal(t, full[:k], s.OrderTopK(k, reverse), "k=%d reverse=%v", k, reverse)
+		}
+	}
+	assert.Equal(t, []int{0, 2, 1}, New([]string{"a", "c", "b"}, String, "s").OrderTopK(10, false))
+	assert.Equal(t, []int{}, s.OrderTopK(-1, false))
+}
+
+func TestSeries_TrimNA(t *testing.T) {
+	leading := New([]interface{}{nil, nil, 1.5, 2.5}, Float, "x").TrimNA()
+	assert.NoError(t, leading.Err)
+	assert.Equal(t, "x", leading.Name)
+	assert.Equal(t, []string{"1.500000", "2.500000"}, leading.Records())
+
+	trailing := New([]interface{}{1, 2, nil}, Int, "i").TrimNA()
+	assert.Equal(t, []string{"1", "2"}, trailing.Records())
+
+	both := New([]interface{}{nil, "a", nil, "b", nil}, String, "s").TrimNA()
+	assert.Equal(t, []string{"a", "NaN", "b"}, both.Records())
+
+	assert.Equal(t, 0, New([]interface{}{nil, nil}, Int, "na").TrimNA().Len())
+	assert.Equal(t, 0, New([]int{}, Int, "empty").TrimNA().Len())
+}
+
+func TestSeries_CastNARoundTrip(t *testing.T) {
+	floats := New([]interface{}{1.5, nil, math.NaN(), 2.5}, Float, "x")
+
+	strs := New(floats, String, "x")
+	assert.Equal(t, []bool{false, true, true, false}, strs.IsNaN())
+	assert.Equal(t, []interface{}{"1.500000", nil, nil, "2.500000"}, []interface{}{strs.Val(0), strs.Val(1), strs.Val(2), strs.Val(3)})
+
+	back := New(strs, Float, "x")
+	assert.Equal(t, []bool{false, true, true, false}, back.IsNaN())
+	assert.Equal(t, []string{"1.500000", "NaN", "NaN", "2.500000"}, back.Records())
+
+	// the NA token of Records is read back as NA
+	records := New(floats.Records(), Float, "x")
+	assert.Equal(t, []bool{false, true, true, false}, records.IsNaN())
+}
+
+func TestSeries_MapParallel(t *testing.T) {
+	values := make([]interface{}, 1001)
+	for i := range values {
+		if i%7 != 0 {
+			values[i] = float64(i) / 4
+		}
+	}
+	s := New(values, Float, "x")
+	square := func(e Element) Element {
+		ret := e.Copy()
+		if !e.IsNA() {
+			ret.Set(e.Float() * e.Float())
+		}
+		return ret
+	}
+
+	expected := s.Map(square)
+	for _, workers := range []int{0, 1, 3, 8, 5000} {
+		result := s.MapParallel(square, workers)
+		assert.Equal(t, "x", result.Name)
+		assert.Equal(t, expected.Records(), result.Records(), "workers=%d", workers)
+	}
+	assert.Equal(t, 0, New([]int{}, Int, "empty").MapParallel(square, 4).Len())
+
+	called := false
+	errored := New([]int{1, 2}, Int, "x")
+	errored.Err = fmt.Errorf("boom")
+	result := errored.MapParallel(func(e Element) Element {
+		called = true
+		return e
+	}, 2)
+	assert.Error(t, result.Err)
+	assert.False(t, called)
+}
+
+func TestSeries_FillNA(t *testing.T) {
+	s := New([]interface{}{nil, 1.0, nil, nil, 4.0, 9.0, nil}, Float, "x")
+	tests := []struct {
+		name     string
+		method   FillMethod
+		value    interface{}
+		expected []string
+	}{
+		{"value", FillValue, 0.5, []string{"0.500000", "1.000000", "0.500000", "0.500000", "4.000000", "9.000000", "0.500000"}},
+		{"forward", FillForward, nil, []string{"NaN", "1.000000", "1.000000", "1.000000", "4.000000", "9.000000", "9.000000"}},
+		{"backward", FillBackward, nil, []string{"1.000000", "1.000000", "4.000000", "4.000000", "4.000000", "9.000000", "NaN"}},
+		{"mean", FillMean, nil, []string{"4.666667", "1.000000", "4.666667", "4.666667", "4.000000", "9.000000", "4.666667"}},
+		{"median", FillMedian, nil, []string{"4.000000", "1.000000", "4.000000", "4.000000", "4.000000", "9.000000", "4.000000"}},
+		{"interpolate", FillInterpolate, nil, []string{"NaN", "1.000000", "2.000000", "3.000000", "4.000000", "9.000000", "NaN"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := s.FillNA(test.method, test.value)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, "x", result.Name)
+			assert.Equal(t, test.expected, result.Records())
+		})
+	}
+	// the receiver is not modified
+	assert.True(t, s.Elem(0).IsNA())
+
+	strs := New([]interface{}{"a", nil}, String, "s")
+	assert.Equal(t, []string{"a", "a"}, strs.FillNA(FillForward, nil).Records())
+	assert.Equal(t, []string{"a", "b"}, strs.FillNA(FillValue, "b").Records())
+	assert.Error(t, strs.FillNA(FillMean, nil).Err)
+	assert.Error(t, s.FillNA(FillValue, nil).Err)
+	assert.Error(t, s.FillNA(FillValue, "x").Err)
+	assert.Error(t, s.FillNA(FillMethod(42), nil).Err)
+}
+
+func TestSeries_SliceByValue(t *testing.T) {
+	s := New([]int{100, 200, 200, 300, 400, 500}, Int, "ts")
+
+	inner := s.SliceByValue(200, 400)
+	assert.NoError(t, inner.Err)
+	assert.Equal(t, "ts", inner.Name)
+	assert.Equal(t, []string{"200", "200", "300", "400"}, inner.Records())
+
+	between := s.SliceByValue(150, 350)
+	assert.Equal(t, []string{"200", "200", "300"}, between.Records())
+
+	empty := s.SliceByValue(310, 390)
+	assert.NoError(t, empty.Err)
+	assert.Equal(t, 0, empty.Len())
+	assert.Equal(t, 0, s.SliceByValue(400, 200).Len())
+
+	unsorted := New([]int{3, 1, 2}, Int, "x")
+	assert.Error(t, unsorted.SliceByValue(1, 2, WithCheckSorted(true)).Err)
+}
+
+func TestSeries_EqualValues(t *testing.T) {
+	a := New([]int{1, 2, 3}, Int, "test")
+	b := New([]int{1, 2, 3}, Int, "test_add_int")
+
+	assert.True(t, a.EqualValues(b))
+	assert.False(t, a.Equal(b))
+	assert.True(t, a.Equal(a.Copy()))
+
+	assert.False(t, a.EqualValues(New([]int{1, 2, 4}, Int, "test")))
+	assert.False(t, a.EqualValues(New([]float64{1, 2, 3}, Float, "test")))
+	assert.False(t, a.EqualValues(New([]int{1, 2}, Int, "test")))
+}
+
+func TestSeries_Cumulative(t *testing.T) {
+	s := New([]interface{}{nil, 3, 1, nil, 4, 2}, Int, "x")
+	assert.Equal(t, []string{"NaN", "3.000000", "4.000000", "NaN", "8.000000", "10.000000"}, s.CumSum().Records())
+	assert.Equal(t, []string{"NaN", "3.000000", "3.000000", "NaN", "12.000000", "24.000000"}, s.CumProd().Records())
+	assert.Equal(t, []string{"NaN", "3.000000", "3.000000", "NaN", "4.000000", "4.000000"}, s.CumMax().Records())
+	assert.Equal(t, []string{"NaN", "3.000000", "1.000000", "NaN", "1.000000", "1.000000"}, s.CumMin().Records())
+	assert.Equal(t, "x", s.CumSum().Name)
+	assert.Equal(t, 24.0, New([]int{1, 2, 3, 4}, Int, "x").Prod())
+
+	assert.Error(t, New([]string{"a"}, String, "s").CumSum().Err)
+}
+
+func TestSeries_Bitset(t *testing.T) {
+	vals := make([]interface{}, 70)
+	for i := range vals {
+		vals[i] = i%3 == 0
+	}
+	vals[5], vals[66] = nil, nil
+	s := New(vals, Bool, "mask")
+
+	values, valid, err := s.Bitset()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(values))
+	assert.Equal(t, 2, len(valid))
+	assert.Equal(t, uint64(0), valid[0]&(1<<5))
+	assert.NotEqual(t, uint64(0), values[0]&(1<<3))
+
+	back := BoolsFromBitset(values, valid, s.Len(), "mask")
+	assert.NoError(t, back.Err)
+	assert.Equal(t, s.Records(), back.Records())
+	assert.True(t, back.Elem(66).IsNA())
+
+	allValid := BoolsFromBitset(values, nil, 4, "m")
+	assert.Equal(t, []string{"true", "false", "false", "true"}, allValid.Records())
+
+	_, _, err = New([]int{1}, Int, "x").Bitset()
+	assert.Error(t, err)
+	assert.Error(t, BoolsFromBitset(values, valid, 200, "m").Err)
+}
+
+func TestSeries_ValidMask(t *testing.T) {
+	s := New([]interface{}{1.5, nil, 3.0, nil}, Float, "load")
+
+	mask := s.ValidMask()
+	assert.NoError(t, mask.Err)
+	assert.Equal(t, Bool, mask.Type())
+	assert.Equal(t, "load", mask.Name)
+	assert.True(t, mask.EqualValues(New(s.IsNaN(), Bool, "").Compare(Eq, false)))
+
+	assert.Equal(t, []string{"1.500000", "3.000000"}, s.Subset(mask).Records())
+}
+
+func TestSeries_QuantileInterp(t *testing.T) {
+	s := New([]interface{}{4.0, 1.0, nil, 3.0, 2.0}, Float, "x")
+
+	tests := []struct {
+		method   QuantileMethod
+		p        float64
+		expected float64
+	}{
+		{QuantileEmpirical, 0.5, 2},
+		{QuantileLinear, 0.5, 2.5},
+		{QuantileLower, 0.5, 2},
+		{QuantileHigher, 0.5, 3},
+		{QuantileNearest, 0.5, 3},
+		{QuantileNearest, 0.4, 2},
+		{QuantileLinear, 0.25, 1.75},
+		{QuantileLinear, 0, 1},
+		{QuantileLinear, 1, 4},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, s.QuantileInterp(test.p, test.method), "method %d, p %v", test.method, test.p)
+	}
+
+	assert.True(t, math.IsNaN(s.QuantileInterp(1.5, QuantileLinear)))
+	assert.True(t, math.IsNaN(New([]string{"a"}, String, "s").QuantileInterp(0.5, QuantileLinear)))
+	assert.True(t, math.IsNaN(New([]interface{}{nil}, Float, "s").QuantileInterp(0.5, QuantileLinear)))
+}
+
+func TestSeries_ExplodeJSON(t *testing.T) {
+	s := New([]interface{}{`["a","b"]`, "plain", nil, `[]`, `[1, {"k":"v"}, null, "c"]`}, String, "tags")
+
+	exploded, parents := s.ExplodeJSON()
+	assert.NoError(t, exploded.Err)
+	assert.Equal(t, "tags", exploded.Name)
+	assert.Equal(t, []string{"a", "b", "plain", "NaN", "NaN", "1", `{"k":"v"}`, "NaN", "c"}, exploded.Records())
+	assert.Equal(t, []int{0, 0, 1, 2, 3, 4, 4, 4, 4}, parents)
+
+	// the parent indexes realign other columns
+	hosts := New([]string{"r1", "r2", "r3", "r4", "r5"}, String, "host")
+	assert.Equal(t, []string{"r1", "r1", "r2", "r3", "r4", "r5", "r5", "r5", "r5"}, hosts.Subset(parents).Records())
+
+	empty, parents := New([]string{}, String, "x").ExplodeJSON()
+	assert.Equal(t, 0, empty.Len())
+	assert.Equal(t, []int{}, parents)
+
+	invalid, _ := New([]int{1}, Int, "x").ExplodeJSON()
+	assert.Error(t, invalid.Err)
+}
+
+func TestAccumulate(t *testing.T) {
+	values := []float64{1.5, -2, 3, 0.5}
+	s := New(values, Float, "x")
+
+	sumSquares := Accumulate(s, 0.0, func(acc float64, e Element) float64 {
+		return acc + e.Float()*e.Float()
+	})
+	expected := 0.0
+	for _, v := range values {
+		expected += v * v
+	}
+	assert.Equal(t, expected, sumSquares)
+
+	// a state machine counting sign changes, skipping NA elements
+	signs := New([]interface{}{1, -1, nil, -2, 3}, Int, "x")
+	type state struct{ prev, changes int }
+	final := Accumulate(signs, state{}, func(st state, e Element) state {
+		if e.IsNA() {
+			return st
+		}
+		v, _ := e.Int()
+		if st.prev != 0 && (v > 0) != (st.prev > 0) {
+			st.changes++
+		}
+		st.prev = v
+		return st
+	})
+	assert.Equal(t, 2, final.changes)
+
+	assert.Equal(t, "init", Accumulate(New([]int{}, Int, "x"), "init", func(acc string, e Element) string { return acc + e.String() }))
+}
+
+func TestSeries_ShiftByGroup(t *testing.T) {
+	s := New([]int{1, 2, 3, 10, 20, 30}, Int, "bytes")
+	groups := []int{7, 7, 7, 9, 9, 9}
+
+	lagged := s.ShiftByGroup(groups, 1, nil)
+	assert.NoError(t, lagged.Err)
+	assert.Equal(t, "bytes", lagged.Name)
+	assert.Equal(t, []string{"NaN", "1", "2", "NaN", "10", "20"}, lagged.Records())
+
+	lead := s.ShiftByGroup(groups, -2, 0)
+	assert.Equal(t, []string{"3", "0", "0", "30", "0", "0"}, lead.Records())
+
+	interleaved := s.ShiftByGroup([]int{1, 2, 1, 2, 1, 2}, 1, nil)
+	assert.Equal(t, []string{"NaN", "NaN", "1", "2", "3", "10"}, interleaved.Records())
+
+	assert.Equal(t, s.Records(), s.ShiftByGroup(groups, 0, nil).Records())
+	assert.Error(t, s.ShiftByGroup([]int{1}, 1, nil).Err)
+}
+
+func TestSeries_MapCached(t *testing.T) {
+	s := New([]interface{}{"up", "down", nil, "up", "up", nil, "down"}, String, "status")
+	calls := 0
+	label := func(e Element) Element {
+		calls++
+		ret := e.Copy()
+		if !e.IsNA() {
+			ret.Set(strings.ToUpper(e.String()) + "!")
+		}
+		return ret
+	}
+
+	cached := s.MapCached(label)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, s.Map(label).Records(), cached.Records())
+	assert.Equal(t, []string{"UP!", "DOWN!", "NaN", "UP!", "UP!", "NaN", "DOWN!"}, cached.Records())
+
+	floats := New([]float64{0.1, 0.1000001, 0.1}, Float, "x")
+	double := func(e Element) Element {
+		ret := e.Copy()
+		ret.Set(e.Float() * 2)
+		return ret
+	}
+	assert.Equal(t, floats.Map(double).Float(), floats.MapCached(double).Float())
+}
+
+func TestSeries_EqualApprox(t *testing.T) {
+	a := New([]interface{}{0.1 + 0.2, nil, 1.0}, Float, "x")
+	b := New([]interface{}{0.3, nil, 1.0 + 1e-9}, Float, "x")
+
+	assert.False(t, a.Equal(b))
+	assert.True(t, a.EqualApprox(b, 1e-6))
+	assert.False(t, a.EqualApprox(b, 1e-12))
+	assert.False(t, a.EqualApprox(New([]interface{}{0.3, 0.0, 1.0}, Float, "x"), 1e-6))
+	assert.False(t, a.EqualApprox(New([]interface{}{0.3, nil, 1.0}, Float, "y"), 1e-6))
+	assert.True(t, New([]string{"a"}, String, "s").EqualApprox(New([]string{"a"}, String, "s"), 0))
+}
+
+func TestSeries_Pad(t *testing.T) {
+	s := New([]interface{}{"ge0", "xe-0/0/1", nil, "é"}, String, "port")
+
+	assert.Equal(t, []string{"   ge0", "xe-0/0/1", "NaN", "     é"}, s.PadLeft(6, ' ').Records())
+	assert.Equal(t, []string{"ge0...", "xe-0/0/1", "NaN", "é....."}, s.PadRight(6, '.').Records())
+	assert.True(t, s.PadLeft(6, ' ').Elem(2).IsNA())
+	assert.Equal(t, "port", s.PadRight(6, ' ').Name)
+	assert.Error(t, New([]int{1}, Int, "x").PadLeft(3, '0').Err)
+}
+
+func TestSeries_FormatFloat(t *testing.T) {
+	s := New([]interface{}{3.14159, nil, 2.0, -0.005}, Float, "load")
+
+	formatted := s.FormatFloat(2)
+	assert.NoError(t, formatted.Err)
+	assert.Equal(t, String, formatted.Type())
+	assert.Equal(t, []string{"3.14", "NaN", "2.00", "-0.01"}, formatted.Records())
+	assert.True(t, formatted.Elem(1).IsNA())
+
+	assert.Equal(t, []string{"7.0"}, New([]int{7}, Int, "x").FormatFloat(1).Records())
+	assert.Error(t, New([]string{"a"}, String, "x").FormatFloat(2).Err)
+}
+
+func TestSeries_ParseNumbers(t *testing.T) {
+	us := New([]interface{}{"1,234.56", "-0.5", nil, "12,000", "n/a"}, String, "amount")
+	parsed := us.ParseNumbers(Float, USNumberFormat)
+	assert.NoError(t, parsed.Err)
+	assert.Equal(t, Float, parsed.Type())
+	assert.Equal(t, "amount", parsed.Name)
+	assert.Equal(t, 1234.56, parsed.Elem(0).Float())
+	assert.Equal(t, -0.5, parsed.Elem(1).Float())
+	assert.True(t, parsed.Elem(2).IsNA())
+	assert.Equal(t, 12000.0, parsed.Elem(3).Float())
+	assert.True(t, parsed.Elem(4).IsNA())
+
+	eu := New([]string{"1.234,56", " 7,5 ", "1.000.000"}, String, "amount")
+	assert.Equal(t, []float64{1234.56, 7.5, 1000000}, eu.ParseNumbers(Float, EUNumberFormat).Float())
+	assert.Equal(t, []string{"NaN", "NaN", "1000000"}, eu.ParseNumbers(Int, EUNumberFormat).Records())
+
+	// the plain strconv parsing is unchanged
+	assert.True(t, New([]string{"1,234.56"}, Float, "x").Elem(0).IsNA())
+	assert.Error(t, New([]int{1}, Int, "x").ParseNumbers(Float, USNumberFormat).Err)
+}
+
+func TestSeries_Astype(t *testing.T) {
+	us := New([]interface{}{"1,234.56", nil, "n/a"}, String, "amount")
+	parsed := us.Astype(Float, ParseNumberFormat(USNumberFormat))
+	assert.NoError(t, parsed.Err)
+	assert.Equal(t, Float, parsed.Type())
+	assert.Equal(t, "amount", parsed.Name)
+	assert.Equal(t, []string{"1234.560000", "NaN", "NaN"}, parsed.Records())
+
+	eu := New([]string{"1.234,56", "1.000.000"}, String, "amount")
+	assert.Equal(t, []float64{1234.56, 1000000}, eu.Astype(Float, ParseNumberFormat(EUNumberFormat)).Float())
+	assert.Equal(t, []string{"NaN", "1000000"}, eu.Astype(Int, ParseNumberFormat(EUNumberFormat)).Records())
+
+	// Without a format strings are parsed with strconv.
+	assert.Equal(t, []string{"NaN", "NaN"}, eu.Astype(Float).Records())
+	assert.Equal(t, []string{"1", "2"}, New([]float64{1.5, 2.5}, Float, "x").Astype(Int).Records())
+	assert.Equal(t, []string{"1.500000"}, New([]float64{1.5}, Float, "x").Astype(String).Records())
+	assert.Error(t, us.Astype(Type("date")).Err)
+}
+
+func TestSeries_Set_NumberFormat(t *testing.T) {
+	s := New([]float64{1, 2, 3}, Float, "amount")
+	s.Set([]int{0, 2}, New([]string{"1.234,5", "7,25"}, String, ""), ParseNumberFormat(EUNumberFormat))
+	assert.NoError(t, s.Err)
+	assert.Equal(t, []float64{1234.5, 2, 7.25}, s.Float())
+
+	i := New([]int{1, 2}, Int, "count")
+	i.Set([]int{1}, New([]string{"12,000"}, String, ""), ParseNumberFormat(USNumberFormat))
+	assert.Equal(t, []string{"1", "12000"}, i.Records())
+
+	// Without a format the strings are parsed with strconv.
+	s.Set([]int{1}, New([]string{"1.234,5"}, String, ""))
+	assert.True(t, s.Elem(1).IsNA())
+}
+
+func TestSeries_ModeBinned(t *testing.T) {
+	s := New([]interface{}{9.8, 10.1, 10.4, 10.3, nil, 3.2, 3.4, 17.9, 10.2}, Float, "latency")
+	assert.InDelta(t, 10.25, s.ModeBinned(0.5), 1e-9)
+	assert.InDelta(t, 11.0, s.ModeBinned(2), 1e-9)
+
+	// ties go to the lowest bin
+	tie := New([]float64{-1.5, -1.2, 4.1, 4.2}, Float, "x")
+	assert.InDelta(t, -1.5, tie.ModeBinned(1), 1e-9)
+
+	assert.InDelta(t, 5.0, New([]int{4, 5, 6, 20}, Int, "x").ModeBinned(10), 1e-9)
+	assert.True(t, math.IsNaN(s.ModeBinned(0)))
+	assert.True(t, math.IsNaN(New([]string{"a"}, String, "x").ModeBinned(1)))
+	assert.True(t, math.IsNaN(New([]interface{}{nil}, Float, "x").ModeBinned(1)))
+}
+
+func TestSeries_IsMonotonic(t *testing.T) {
+	increasing := New([]int{1, 2, 5, 9}, Int, "ts")
+	plateau := New([]int{1, 2, 2, 3}, Int, "ts")
+	dip := New([]int{1, 3, 2, 4}, Int, "ts")
+	withNA := New([]interface{}{1.0, nil, 2.0}, Float, "ts")
+
+	assert.True(t, increasing.IsMonotonicIncreasing())
+	assert.True(t, increasing.IsMonotonicIncreasing(MonotonicStrict(true)))
+	assert.False(t, increasing.IsMonotonicDecreasing())
+
+	assert.True(t, plateau.IsMonotonicIncreasing())
+	assert.False(t, plateau.IsMonotonicIncreasing(MonotonicStrict(true)))
+
+	assert.False(t, dip.IsMonotonicIncreasing())
+	assert.False(t, dip.IsMonotonicDecreasing())
+
+	assert.True(t, New([]string{"c", "b", "b", "a"}, String, "s").IsMonotonicDecreasing())
+
+	assert.False(t, withNA.IsMonotonicIncreasing())
+	assert.True(t, withNA.IsMonotonicIncreasing(MonotonicSkipNA(true)))
+	assert.True(t, New([]int{}, Int, "x").IsMonotonicIncreasing())
+}
+
+func TestSeries_Align(t *testing.T) {
+	short := New([]int{1, 2, 3}, Int, "a")
+	long := New([]int{10, 20, 30, 40, 50}, Int, "b")
+
+	assert.Error(t, short.Add(long, "sum").Err)
+
+	a, b := short.Align(long, nil)
+	assert.Equal(t, 5, a.Len())
+	assert.Equal(t, "a", a.Name)
+	assert.Equal(t, []string{"1", "2", "3", "NaN", "NaN"}, a.Records())
+	assert.Equal(t, long.Records(), b.Records())
+	assert.Equal(t, 3, short.Len())
+
+	a, b = short.Align(long, 0)
+	sum := a.Add(b, "sum")
+	assert.NoError(t, sum.Err)
+	assert.Equal(t, []string{"11", "22", "33", "40", "50"}, sum.Records())
+
+	b, a = long.Align(short, 0)
+	assert.Equal(t, 5, a.Len())
+	assert.Equal(t, 5, b.Len())
+}
+
+func TestSeries_WeightedMean(t *testing.T) {
+	latency := New([]interface{}{10.0, 20.0, nil, 40.0}, Float, "latency")
+	traffic := New([]interface{}{1, 3, 5, nil}, Int, "traffic")
+
+	mean, err := latency.WeightedMean(traffic)
+	assert.NoError(t, err)
+	assert.InDelta(t, (10.0*1+20.0*3)/4, mean, 1e-9)
+
+	zero, err := latency.WeightedMean(New([]int{0, 0, 0, 0}, Int, "w"))
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(zero))
+
+	_, err = latency.WeightedMean(New([]int{1, 2}, Int, "w"))
+	assert.Error(t, err)
+	_, err = latency.WeightedMean(New([]string{"a", "b", "c", "d"}, String, "w"))
+	assert.Error(t, err)
+}
+
+func TestSeries_Histogram(t *testing.T) {
+	s := New([]interface{}{0.0, 1.0, 1.5, 2.0, nil, 2.5, 3.0, 3.9, 4.0}, Float, "x")
+
+	edges, counts, err := s.Histogram(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 1, 2, 3, 4}, edges)
+	assert.Equal(t, []int{1, 2, 2, 3}, counts)
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	assert.Equal(t, 8, total)
+
+	edges, counts, err = New([]int{5, 5, 5}, Int, "x").Histogram(10)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{4.5, 5.5}, edges)
+	assert.Equal(t, []int{3}, counts)
+
+	edges, counts, err = New([]interface{}{nil, nil}, Float, "x").Histogram(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 1}, edges)
+	assert.Equal(t, []int{0}, counts)
+
+	_, _, err = s.Histogram(0)
+	assert.Error(t, err)
+	_, _, err = New([]string{"a"}, String, "x").Histogram(2)
+	assert.Error(t, err)
+}
+
+func TestSeries_BoolIntCasts(t *testing.T) {
+	flags := New([]interface{}{true, false, nil, true}, Bool, "up")
+	ints := flags.BoolToInt()
+	assert.NoError(t, ints.Err)
+	assert.Equal(t, Int, ints.Type())
+	assert.Equal(t, "up", ints.Name)
+	assert.Equal(t, []string{"1", "0", "NaN", "1"}, ints.Records())
+
+	counts := New([]interface{}{0, 1, 5, -2, nil}, Int, "flaps")
+	bools := counts.IntToBool()
+	assert.NoError(t, bools.Err)
+	assert.Equal(t, Bool, bools.Type())
+	assert.Equal(t, []string{"false", "true", "true", "true", "NaN"}, bools.Records())
+
+	// round trip
+	assert.Equal(t, flags.Records(), flags.BoolToInt().IntToBool().Records())
+
+	assert.Error(t, counts.BoolToInt().Err)
+	assert.Error(t, flags.IntToBool().Err)
+}
+
+func TestNewFromChannel(t *testing.T) {
+	ch := make(chan interface{})
+	go func() {
+		for _, v := range []interface{}{1, 2, "3", "x", nil, 6.0} {
+			ch <- v
+		}
+		close(ch)
+	}()
+
+	s := NewFromChannel(ch, Int, "flaps")
+	assert.NoError(t, s.Err)
+	assert.Equal(t, "flaps", s.Name)
+	assert.Equal(t, Int, s.Type())
+	assert.Equal(t, []string{"1", "2", "3", "NaN", "NaN", "6"}, s.Records())
+
+	empty := make(chan interface{})
+	close(empty)
+	e := NewFromChannel(empty, Float, "empty")
+	assert.Equal(t, Float, e.Type())
+	assert.Equal(t, 0, e.Len())
+}
@@ -1,5 +1,11 @@
 package series
 
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
 // RollingWindow is used for rolling window calculations.
 type RollingWindow struct {
 	window int
@@ -34,6 +40,25 @@ func (r RollingWindow) StdDev() (s Series) {
 	return
 }
 
+// RollingZScore returns, for every position, how many rolling standard
+// deviations the value lies from the rolling mean of the window ending at it.
+// Warm-up positions and windows with zero deviation are NaN.
+func (s Series) RollingZScore(window int) Series {
+	r := s.Rolling(window)
+	mean := r.Mean()
+	stdDev := r.StdDev()
+	values := make([]interface{}, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		m, sd := mean.Elem(i).Float(), stdDev.Elem(i).Float()
+		if e.IsNA() || math.IsNaN(m) || math.IsNaN(sd) || sd == 0 {
+			continue
+		}
+		values[i] = (e.Float() - m) / sd
+	}
+	return New(values, Float, s.Name)
+}
+
 func (r RollingWindow) getBlocks() (blocks []Series) {
 	for i := 1; i <= r.series.Len(); i++ {
 		if i < r.window {
@@ -50,3 +75,298 @@ func (r RollingWindow) getBlocks() (blocks []Series) {
 
 	return
 }
+
+// RollingOption is the type used to configure rolling calculations
+type RollingOption func(*rollingOptions)
+
+type rollingOptions struct {
+	// skipNA computes the statistic over the valid elements of a window
+	// instead of yielding NaN for windows holding NA elements.
+	skipNA bool
+	// kahan sums each window with a compensation term, so small values
+	// aren't lost next to large ones.
+	kahan bool
+}
+
+// RollingSkipNA sets the skipNA option for rollingOptions.
+func RollingSkipNA(b bool) RollingOption {
+	return func(o *rollingOptions) {
+		o.skipNA = b
+	}
+}
+
+// RollingKahan sets the kahan option for rollingOptions.
+func RollingKahan(b bool) RollingOption {
+	return func(o *rollingOptions) {
+		o.kahan = b
+	}
+}
+
+// RollingSum returns the sum of the window ending at every position. Warm-up
+// positions are NaN, as are windows holding NA elements unless RollingSkipNA is
+// set, in which case only windows without any valid element are NaN. Every
+// window is summed on its own rather than by sliding a running sum, so values
+// of very different magnitude or infinities don't leak into later windows.
+// With RollingKahan each window is summed with compensation.
+func (s Series) RollingSum(window int, opts ...RollingOption) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if window <= 0 {
+		ret := New([]float64{}, Float, s.Name)
+		ret.Err = fmt.Errorf("rolling sum: window must be positive, got %d", window)
+		return ret
+	}
+	options := rollingOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	values := make([]interface{}, s.Len())
+	for i := window - 1; i < s.Len(); i++ {
+		var acc kahanSum
+		valid, nas := 0, 0
+		for j := i - window + 1; j <= i; j++ {
+			e := s.elements.Elem(j)
+			if e.IsNA() {
+				nas++
+				continue
+			}
+			if options.kahan {
+				acc.add(e.Float())
+			} else {
+				acc.sum += e.Float()
+			}
+			valid++
+		}
+		if valid == 0 || (nas > 0 && !options.skipNA) {
+			continue
+		}
+		values[i] = acc.value()
+	}
+	return New(values, Float, s.Name)
+}
+
+// kahanSum accumulates float64 values with Neumaier's variant of Kahan
+// summation, which also compensates when the added value outweighs the sum.
+type kahanSum struct {
+	sum, c float64
+}
+
+func (k *kahanSum) add(f float64) {
+	t := k.sum + f
+	if math.IsInf(t, 0) || math.IsNaN(t) {
+		// The compensation of an infinite sum is NaN; keep it out.
+		k.sum = t
+		return
+	}
+	if math.Abs(k.sum) >= math.Abs(f) {
+		k.c += (k.sum - t) + f
+	} else {
+		k.c += (f - t) + k.sum
+	}
+	k.sum = t
+}
+
+func (k kahanSum) value() float64 {
+	return k.sum + k.c
+}
+
+// RollingMedian returns the median of the window ending at every position,
+// keeping the window sorted as it slides instead of sorting each block. Warm-up
+// positions are NaN, as are windows holding NA elements unless RollingSkipNA is
+// set, in which case only windows without any valid element are NaN.
+func (s Series) RollingMedian(window int, opts ...RollingOption) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if window <= 0 {
+		ret := New([]float64{}, Float, s.Name)
+		ret.Err = fmt.Errorf("rolling median: window must be positive, got %d", window)
+		return ret
+	}
+	options := rollingOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	values := make([]interface{}, s.Len())
+	sorted := make([]float64, 0, window)
+	nas := 0
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); e.IsNA() {
+			nas++
+		} else {
+			f := e.Float()
+			k := sort.SearchFloat64s(sorted, f)
+			sorted = append(sorted, 0)
+			copy(sorted[k+1:], sorted[k:])
+			sorted[k] = f
+		}
+		if i >= window {
+			if e := s.elements.Elem(i - window); e.IsNA() {
+				nas--
+			} else {
+				k := sort.SearchFloat64s(sorted, e.Float())
+				sorted = append(sorted[:k], sorted[k+1:]...)
+			}
+		}
+		if i < window-1 || len(sorted) == 0 || (nas > 0 && !options.skipNA) {
+			continue
+		}
+		n := len(sorted)
+		if n%2 != 0 {
+			values[i] = sorted[n/2]
+		} else {
+			values[i] = (sorted[n/2-1] + sorted[n/2]) * 0.5
+		}
+	}
+	return New(values, Float, s.Name)
+}
+
+// RollingRank returns, for every position, the percentile rank in (0, 1] of
+// the element within the window ending at it, ties taking their average rank.
+// The largest element of a window ranks 1. Warm-up positions and NA elements
+// are NaN, as are windows holding NA elements unless RollingSkipNA is set, in
+// which case the rank is taken among the valid elements.
+func (s Series) RollingRank(window int, opts ...RollingOption) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if window <= 0 {
+		ret := New([]float64{}, Float, s.Name)
+		ret.Err = fmt.Errorf("rolling rank: window must be positive, got %d", window)
+		return ret
+	}
+	options := rollingOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	values := make([]interface{}, s.Len())
+	for i := window - 1; i < s.Len(); i++ {
+		cur := s.elements.Elem(i)
+		if cur.IsNA() {
+			continue
+		}
+		f := cur.Float()
+		var less, equal, n int
+		for j := i - window + 1; j <= i; j++ {
+			e := s.elements.Elem(j)
+			if e.IsNA() {
+				if !options.skipNA {
+					n = -1
+					break
+				}
+				continue
+			}
+			n++
+			switch v := e.Float(); {
+			case v < f:
+				less++
+			case v == f:
+				equal++
+			}
+		}
+		if n <= 0 {
+			continue
+		}
+		values[i] = (float64(less) + float64(equal+1)/2) / float64(n)
+	}
+	return New(values, Float, s.Name)
+}
+
+// ExpandingWindow is used for expanding window calculations, where every
+// position aggregates all the elements from the start of the Series up to it.
+type ExpandingWindow struct {
+	minPeriods int
+	series     Series
+}
+
+// Expanding creates new ExpandingWindow. Positions where fewer than minPeriods
+// valid elements have been seen yield NaN.
+func (s Series) Expanding(minPeriods int) ExpandingWindow {
+	return ExpandingWindow{
+		minPeriods: minPeriods,
+		series:     s,
+	}
+}
+
+// Mean returns the expanding mean.
+func (e ExpandingWindow) Mean() Series {
+	sum := 0.0
+	return e.apply("Mean", func(n int, f float64) float64 {
+		sum += f
+		return sum / float64(n)
+	})
+}
+
+// Sum returns the expanding sum.
+func (e ExpandingWindow) Sum() Series {
+	sum := 0.0
+	return e.apply("Sum", func(n int, f float64) float64 {
+		sum += f
+		return sum
+	})
+}
+
+// Max returns the expanding maximum.
+func (e ExpandingWindow) Max() Series {
+	max := math.Inf(-1)
+	return e.apply("Max", func(n int, f float64) float64 {
+		max = math.Max(max, f)
+		return max
+	})
+}
+
+// Min returns the expanding minimum.
+func (e ExpandingWindow) Min() Series {
+	min := math.Inf(1)
+	return e.apply("Min", func(n int, f float64) float64 {
+		min = math.Min(min, f)
+		return min
+	})
+}
+
+// Std returns the expanding sample standard deviation, NaN while fewer than
+// two valid elements have been seen.
+func (e ExpandingWindow) Std() Series {
+	// Welford's online algorithm
+	mean, m2 := 0.0, 0.0
+	return e.apply("Std", func(n int, f float64) float64 {
+		delta := f - mean
+		mean += delta / float64(n)
+		m2 += delta * (f - mean)
+		if n < 2 {
+			return math.NaN()
+		}
+		return math.Sqrt(m2 / float64(n-1))
+	})
+}
+
+// apply feeds every valid element to f together with the number of valid
+// elements seen so far. NA elements are skipped and yield NaN.
+func (e ExpandingWindow) apply(name string, f func(n int, value float64) float64) Series {
+	s := e.series
+	if err := s.Err; err != nil {
+		return s
+	}
+	if !isNumericType(s.t) {
+		return Series{Err: fmt.Errorf("expanding: unsupported series type %s", s.t)}
+	}
+	values := make([]float64, s.Len())
+	n := 0
+	for i := 0; i < s.Len(); i++ {
+		elem := s.elements.Elem(i)
+		if elem.IsNA() {
+			values[i] = math.NaN()
+			continue
+		}
+		n++
+		values[i] = f(n, elem.Float())
+		if n < e.minPeriods {
+			values[i] = math.NaN()
+		}
+	}
+	return New(values, Float, name)
+}
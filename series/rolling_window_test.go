@@ -1,6 +1,7 @@
 package series
 
 import (
+	"fmt"
 	"math"
 	"strings"
 	"testing"
@@ -83,3 +84,282 @@ func TestSeries_RollingStdDev(t *testing.T) {
 		}
 	}
 }
+
+func TestSeries_RollingZScore(t *testing.T) {
+	values := []float64{10, 10.2, 9.8, 10.1, 9.9, 10, 10.2, 9.8, 10.1, 9.9, 10, 10.2, 9.8, 10.1, 50, 10}
+	received := Floats(values).RollingZScore(10)
+
+	if received.Len() != len(values) {
+		t.Fatalf("Expected length %v, received %v", len(values), received.Len())
+	}
+	for i := 0; i < 9; i++ {
+		if !received.Elem(i).IsNA() {
+			t.Errorf("Expected warm-up position %v to be NaN, received %v", i, received.Elem(i))
+		}
+	}
+	for i := 9; i < 14; i++ {
+		if z := received.Elem(i).Float(); math.Abs(z) > 2 {
+			t.Errorf("Expected small z-score at %v, received %v", i, z)
+		}
+	}
+	if z := received.Elem(14).Float(); z < 2.5 {
+		t.Errorf("Expected spike z-score above threshold, received %v", z)
+	}
+
+	flat := Ints([]int{3, 3, 3, 3}).RollingZScore(2)
+	for i := 0; i < flat.Len(); i++ {
+		if !flat.Elem(i).IsNA() {
+			t.Errorf("Expected zero deviation window %v to be NaN, received %v", i, flat.Elem(i))
+		}
+	}
+}
+
+func TestSeries_RollingMedian(t *testing.T) {
+	tests := []struct {
+		window   int
+		series   Series
+		opts     []RollingOption
+		expected Series
+	}{
+		{
+			3,
+			Ints([]int{1, 100, 2, 3, 50, 4, 4}),
+			nil,
+			Floats([]float64{math.NaN(), math.NaN(), 2.0, 3.0, 3.0, 4.0, 4.0}),
+		},
+		{
+			2,
+			Floats([]float64{1.0, 2.0, 4.0}),
+			nil,
+			Floats([]float64{math.NaN(), 1.5, 3.0}),
+		},
+		{
+			3,
+			Floats([]interface{}{1.0, 5.0, nil, 3.0, 2.0, 7.0}),
+			nil,
+			Floats([]float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN(), 3.0}),
+		},
+		{
+			3,
+			Floats([]interface{}{1.0, 5.0, nil, nil, nil, 2.0}),
+			[]RollingOption{RollingSkipNA(true)},
+			Floats([]float64{math.NaN(), math.NaN(), 3.0, 5.0, math.NaN(), 2.0}),
+		},
+		{
+			1,
+			Floats([]float64{}),
+			nil,
+			Floats([]float64{}),
+		},
+	}
+
+	for testnum, test := range tests {
+		expected := test.expected
+		received := test.series.RollingMedian(test.window, test.opts...)
+		if received.Len() != expected.Len() {
+			t.Fatalf("Test:%v\nExpected length %v, received %v", testnum, expected.Len(), received.Len())
+		}
+
+		for i := 0; i < expected.Len(); i++ {
+			if strings.Compare(expected.Elem(i).String(),
+				received.Elem(i).String()) != 0 {
+				t.Errorf(
+					"Test:%v\nExpected:\n%v\nReceived:\n%v",
+					testnum, expected, received,
+				)
+			}
+		}
+	}
+
+	if err := Ints([]int{1, 2}).RollingMedian(0).Err; err == nil {
+		t.Errorf("Expected error for non-positive window")
+	}
+}
+
+func TestSeries_RollingRank(t *testing.T) {
+	increasing := Ints([]int{1, 2, 3, 4, 5, 6}).RollingRank(3)
+	for i := 0; i < 2; i++ {
+		if !increasing.Elem(i).IsNA() {
+			t.Errorf("Expected warm-up position %v to be NaN, received %v", i, increasing.Elem(i))
+		}
+	}
+	for i := 2; i < increasing.Len(); i++ {
+		if r := increasing.Elem(i).Float(); math.Abs(r-1) > 1e-9 {
+			t.Errorf("Expected rank 1 at %v, received %v", i, r)
+		}
+	}
+
+	tests := []struct {
+		series   Series
+		opts     []RollingOption
+		expected Series
+	}{
+		{
+			Floats([]float64{3, 1, 2, 2, 5}),
+			nil,
+			Floats([]float64{math.NaN(), math.NaN(), 2.0 / 3, 2.5 / 3, 1}),
+		},
+		{
+			Floats([]interface{}{1.0, nil, 3.0, 2.0, 0.5}),
+			nil,
+			Floats([]float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 1.0 / 3}),
+		},
+		{
+			Floats([]interface{}{1.0, nil, 3.0, 2.0, 0.5}),
+			[]RollingOption{RollingSkipNA(true)},
+			Floats([]float64{math.NaN(), math.NaN(), 1, 0.5, 1.0 / 3}),
+		},
+	}
+	for testnum, test := range tests {
+		received := test.series.RollingRank(3, test.opts...)
+		for i := 0; i < test.expected.Len(); i++ {
+			if test.expected.Elem(i).String() != received.Elem(i).String() {
+				t.Errorf("Test:%v\nExpected:\n%v\nReceived:\n%v", testnum, test.expected, received)
+			}
+		}
+	}
+
+	if err := Ints([]int{1, 2}).RollingRank(0).Err; err == nil {
+		t.Errorf("Expected error for non-positive window")
+	}
+}
+
+func TestSeries_RollingSum(t *testing.T) {
+	tests := []struct {
+		window   int
+		series   Series
+		opts     []RollingOption
+		expected Series
+	}{
+		{
+			3,
+			Ints([]int{1, 2, 3, 4, 5}),
+			nil,
+			Floats([]float64{math.NaN(), math.NaN(), 6, 9, 12}),
+		},
+		{
+			2,
+			Floats([]interface{}{1.0, nil, 3.0, 4.0}),
+			nil,
+			Floats([]float64{math.NaN(), math.NaN(), math.NaN(), 7}),
+		},
+		{
+			2,
+			Floats([]interface{}{1.0, nil, nil, 4.0}),
+			[]RollingOption{RollingSkipNA(true)},
+			Floats([]float64{math.NaN(), 1, math.NaN(), 4}),
+		},
+		{
+			2,
+			Floats([]float64{1e16, 1, 1, 1}),
+			[]RollingOption{RollingKahan(true)},
+			Floats([]float64{math.NaN(), 1e16 + 1, 2, 2}),
+		},
+		{
+			2,
+			Floats([]float64{1e20, 1, 1, 1, 1}),
+			nil,
+			Floats([]float64{math.NaN(), 1e20, 2, 2, 2}),
+		},
+		{
+			2,
+			Floats([]float64{1, math.Inf(1), 1, 1, 1}),
+			nil,
+			Floats([]float64{math.NaN(), math.Inf(1), math.Inf(1), 2, 2}),
+		},
+		{
+			2,
+			Floats([]float64{1, math.Inf(1), 1, 1, 1}),
+			[]RollingOption{RollingKahan(true)},
+			Floats([]float64{math.NaN(), math.Inf(1), math.Inf(1), 2, 2}),
+		},
+		{
+			2,
+			Floats([]float64{math.Inf(1), math.Inf(-1), 1, 1}),
+			[]RollingOption{RollingKahan(true)},
+			Floats([]float64{math.NaN(), math.NaN(), math.Inf(-1), 2}),
+		},
+	}
+	for testnum, test := range tests {
+		received := test.series.RollingSum(test.window, test.opts...)
+		if received.Len() != test.expected.Len() {
+			t.Fatalf("Test:%v\nExpected length %v, received %v", testnum, test.expected.Len(), received.Len())
+		}
+		for i := 0; i < test.expected.Len(); i++ {
+			if test.expected.Elem(i).String() != received.Elem(i).String() {
+				t.Errorf("Test:%v\nExpected:\n%v\nReceived:\n%v", testnum, test.expected, received)
+			}
+		}
+	}
+
+	// Small values are lost next to a large one unless the window sum is
+	// compensated.
+	window := Floats([]float64{1e16, 1, 1, -1e16})
+	if sum := window.RollingSum(4).Elem(3).Float(); sum != 0 {
+		t.Errorf("Expected naive window sum 0, received %v", sum)
+	}
+	if sum := window.RollingSum(4, RollingKahan(true)).Elem(3).Float(); sum != 2 {
+		t.Errorf("Expected compensated window sum 2, received %v", sum)
+	}
+
+	values := make([]float64, 1000000)
+	for i := range values {
+		values[i] = 0.1
+	}
+	s := Floats(values)
+	if total := s.KahanSum(); math.Abs(total-100000) >= math.Abs(s.Sum()-100000) {
+		t.Errorf("Expected KahanSum %v to be closer to 100000 than Sum %v", total, s.Sum())
+	}
+
+	if err := Ints([]int{1, 2}).RollingSum(0).Err; err == nil {
+		t.Errorf("Expected error for non-positive window")
+	}
+}
+
+func TestSeries_Expanding(t *testing.T) {
+	values := []int{4, 2, 6, 8, 5}
+	expanding := Ints(values).Expanding(1)
+
+	mean := expanding.Mean()
+	sum := 0.0
+	for i, v := range values {
+		sum += float64(v)
+		if got, want := mean.Elem(i).Float(), sum/float64(i+1); math.Abs(got-want) > 1e-12 {
+			t.Errorf("Expected mean %v at %v, received %v", want, i, got)
+		}
+	}
+
+	tests := []struct {
+		received Series
+		expected Series
+	}{
+		{expanding.Sum(), Floats([]float64{4, 6, 12, 20, 25})},
+		{expanding.Max(), Floats([]float64{4, 4, 6, 8, 8})},
+		{expanding.Min(), Floats([]float64{4, 2, 2, 2, 2})},
+		{Floats([]float64{1, 2, 3}).Expanding(1).Std(), Floats([]float64{math.NaN(), 0.7071067811865476, 1})},
+		{Floats([]interface{}{1.0, nil, 3.0, 5.0}).Expanding(2).Mean(), Floats([]float64{math.NaN(), math.NaN(), 2, 3})},
+	}
+	for testnum, test := range tests {
+		if test.received.Len() != test.expected.Len() {
+			t.Fatalf("Test:%v\nExpected length %v, received %v", testnum, test.expected.Len(), test.received.Len())
+		}
+		for i := 0; i < test.expected.Len(); i++ {
+			if strings.Compare(test.expected.Elem(i).String(),
+				test.received.Elem(i).String()) != 0 {
+				t.Errorf(
+					"Test:%v\nExpected:\n%v\nReceived:\n%v",
+					testnum, test.expected, test.received,
+				)
+			}
+		}
+	}
+
+	if err := Strings([]string{"a", "b"}).Expanding(1).Mean().Err; err == nil {
+		t.Errorf("Expected error for non-numeric series")
+	}
+	errored := Ints([]int{1, 2})
+	errored.Err = fmt.Errorf("boom")
+	if err := errored.Expanding(1).Sum().Err; err == nil {
+		t.Errorf("Expected error to be propagated")
+	}
+}
@@ -1,10 +1,19 @@
 package series
 
 import (
+	"container/heap"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"reflect"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"math"
 
@@ -243,11 +252,22 @@ func (s *Series) Error() error {
 }
 
 // Append adds new elements to the end of the Series. When using Append, the
-// Series is modified in place.
+// Series is modified in place. Appending a numeric Series of a different type
+// promotes the Series to Float, while mixing String and numeric Series, in
+// either direction, sets Err.
 func (s *Series) Append(values interface{}) {
 	if err := s.Err; err != nil {
 		return
 	}
+	if x, ok := values.(Series); ok && x.t != s.t {
+		switch {
+		case isNumericType(s.t) && isNumericType(x.t):
+			*s = New(*s, Float, s.Name)
+		case isNumericType(s.t) && x.t == String, s.t == String && isNumericType(x.t):
+			s.Err = fmt.Errorf("append error: can't mix %s and %s series", s.t, x.t)
+			return
+		}
+	}
 	news := New(values, s.t, s.Name)
 	switch s.t {
 	case String:
@@ -262,7 +282,8 @@ func (s *Series) Append(values interface{}) {
 }
 
 // Concat concatenates two series together. It will return a new Series with the
-// combined elements of both Series.
+// combined elements of both Series. Concatenating Int and Float Series yields a
+// Float Series, following the same promotion rules as the arithmetic methods.
 func (s Series) Concat(x Series) Series {
 	if err := s.Err; err != nil {
 		return s
@@ -273,9 +294,18 @@ func (s Series) Concat(x Series) Series {
 	}
 	y := s.Copy()
 	y.Append(x)
+	if err := y.Err; err != nil {
+		s.Err = fmt.Errorf("concat error: %v", err)
+		return s
+	}
 	return y
 }
 
+// isNumericType reports whether the given Type holds numeric values.
+func isNumericType(t Type) bool {
+	return t == Int || t == Float
+}
+
 // Subset returns a subset of the series based on the given Indexes.
 func (s Series) Subset(indexes Indexes) Series {
 	if err := s.Err; err != nil {
@@ -322,8 +352,9 @@ func (s Series) Subset(indexes Indexes) Series {
 }
 
 // Set sets the values on the indexes of a Series and returns the reference
-// for itself. The original Series is modified.
-func (s Series) Set(indexes Indexes, newvalues Series) Series {
+// for itself. The original Series is modified. String values set on an Int or
+// Float Series are parsed with strconv unless ParseNumberFormat is given.
+func (s Series) Set(indexes Indexes, newvalues Series, opts ...ParseOption) Series {
 	if err := s.Err; err != nil {
 		return s
 	}
@@ -340,12 +371,16 @@ func (s Series) Set(indexes Indexes, newvalues Series) Series {
 		s.Err = fmt.Errorf("set error: dimensions mismatch")
 		return s
 	}
+	cfg := parseOptions{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	for k, i := range idx {
 		if i < 0 || i >= s.Len() {
 			s.Err = fmt.Errorf("set error: index out of range")
 			return s
 		}
-		s.elements.Elem(i).Set(newvalues.elements.Elem(k))
+		s.elements.Elem(i).Set(cfg.parseValue(newvalues.elements.Elem(k), s.t))
 	}
 	return s
 }
@@ -428,9 +463,21 @@ func (s Series) IsNaN() []bool {
 	return ret
 }
 
+// ValidMask returns a Bool Series, named like the Series, that is true where
+// the element is not NA. It is the negation of IsNaN, ready to be passed to
+// Subset or Compare.
+func (s Series) ValidMask() Series {
+	ret := make([]bool, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		ret[i] = !s.elements.Elem(i).IsNA()
+	}
+	return New(ret, Bool, s.Name)
+}
+
 // Compare compares the values of a Series with other elements. To do so, the
 // elements with are to be compared are first transformed to a Series of the same
-// type as the caller.
+// type as the caller. Comparisons involving an NA operand are unknown and yield
+// NA in the resulting mask, except for CompFunc where the function decides.
 func (s Series) Compare(comparator Comparator, comparando interface{}) Series {
 	if err := s.Err; err != nil {
 		return s
@@ -473,11 +520,16 @@ func (s Series) Compare(comparator Comparator, comparando interface{}) Series {
 		return Bools(bools)
 	}
 
+	nas := make([]bool, s.Len())
 	comp := New(comparando, s.t, "")
 	// In comparator comparison
 	if comparator == In {
 		for i := 0; i < s.Len(); i++ {
 			e := s.elements.Elem(i)
+			if e.IsNA() {
+				nas[i] = true
+				continue
+			}
 			b := false
 			for j := 0; j < comp.Len(); j++ {
 				m := comp.elements.Elem(j)
@@ -494,13 +546,17 @@ func (s Series) Compare(comparator Comparator, comparando interface{}) Series {
 			}
 			bools[i] = b
 		}
-		return Bools(bools)
+		return maskFromBools(bools, nas)
 	}
 
 	// Single element comparison
 	if comp.Len() == 1 {
 		for i := 0; i < s.Len(); i++ {
 			e := s.elements.Elem(i)
+			if e.IsNA() || comp.elements.Elem(0).IsNA() {
+				nas[i] = true
+				continue
+			}
 			c, err := compareElements(e, comp.elements.Elem(0), comparator)
 			if err != nil {
 				s = s.Empty()
@@ -509,7 +565,7 @@ func (s Series) Compare(comparator Comparator, comparando interface{}) Series {
 			}
 			bools[i] = c
 		}
-		return Bools(bools)
+		return maskFromBools(bools, nas)
 	}
 
 	// Multiple element comparison
@@ -520,6 +576,10 @@ func (s Series) Compare(comparator Comparator, comparando interface{}) Series {
 	}
 	for i := 0; i < s.Len(); i++ {
 		e := s.elements.Elem(i)
+		if e.IsNA() || comp.elements.Elem(i).IsNA() {
+			nas[i] = true
+			continue
+		}
 		c, err := compareElements(e, comp.elements.Elem(i), comparator)
 		if err != nil {
 			s = s.Empty()
@@ -528,7 +588,19 @@ func (s Series) Compare(comparator Comparator, comparando interface{}) Series {
 		}
 		bools[i] = c
 	}
-	return Bools(bools)
+	return maskFromBools(bools, nas)
+}
+
+// maskFromBools builds a Bool Series from bools where the positions marked in
+// nas are set to NA.
+func maskFromBools(bools, nas []bool) Series {
+	ret := Bools(bools)
+	for i, na := range nas {
+		if na {
+			ret.elements.Elem(i).Set(nil)
+		}
+	}
+	return ret
 }
 
 // Copy will return a copy of the Series.
@@ -678,16 +750,19 @@ func parseIndexes(l int, indexes Indexes) ([]int, error) {
 		if err := s.Err; err != nil {
 			return nil, fmt.Errorf("indexing error: new values has errors: %v", err)
 		}
-		if s.HasNaN() {
-			return nil, fmt.Errorf("indexing error: indexes contain NaN")
-		}
 		switch s.t {
 		case Int:
+			if s.HasNaN() {
+				return nil, fmt.Errorf("indexing error: indexes contain NaN")
+			}
 			return s.Int()
 		case Bool:
-			bools, err := s.Bool()
-			if err != nil {
-				return nil, fmt.Errorf("indexing error: %v", err)
+			// NA entries of a mask are unknown and therefore excluded
+			bools := make([]bool, s.Len())
+			for i := range bools {
+				if e := s.elements.Elem(i); !e.IsNA() {
+					bools[i], _ = e.Bool()
+				}
 			}
 			return parseIndexes(l, bools)
 		default:
@@ -725,6 +800,15 @@ func (s Series) Order(reverse bool) []int {
 	return append(ret, nasIdx...)
 }
 
+// Sort returns a sorted copy of the Series. NaN elements are pushed to the end
+// by order of appearance, as with Order.
+func (s Series) Sort(reverse bool) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	return s.Subset(s.Order(reverse))
+}
+
 type indexedElement struct {
 	index   int
 	element Element
@@ -742,10 +826,17 @@ func (s Series) StdDev() float64 {
 	return stdDev
 }
 
-// Mean calculates the average value of a series
+// Mean calculates the average value of a series. NA elements are skipped.
 func (s Series) Mean() float64 {
-	stdDev := stat.Mean(s.Float(), nil)
-	return stdDev
+	values := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			continue
+		}
+		values = append(values, e.Float())
+	}
+	return stat.Mean(values, nil)
 }
 
 // Median calculates the middle or median value, as opposed to
@@ -851,6 +942,80 @@ func (s Series) Quantile(p float64) float64 {
 	return stat.Quantile(p, stat.Empirical, ordered, nil)
 }
 
+// ModeBinned splits the values of a numeric Series into bins of the given
+// width, aligned on multiples of it, and returns the center of the most
+// populated bin, which makes more sense than an exact mode for noisy floats.
+// Ties go to the bin with the lowest center. NA elements are ignored. It
+// returns NaN for non-numeric Series, a non-positive width or no valid values.
+func (s Series) ModeBinned(binWidth float64) float64 {
+	if !isNumericType(s.t) || !(binWidth > 0) {
+		return math.NaN()
+	}
+	counts := make(map[float64]int)
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			counts[math.Floor(e.Float()/binWidth)]++
+		}
+	}
+	best, bestCount := math.NaN(), 0
+	for bin, count := range counts {
+		if count > bestCount || (count == bestCount && bin < best) {
+			best, bestCount = bin, count
+		}
+	}
+	return (best + 0.5) * binWidth
+}
+
+// QuantileMethod selects how QuantileInterp picks a value when the quantile
+// falls between two samples.
+type QuantileMethod int
+
+// Supported QuantileMethods
+const (
+	QuantileEmpirical QuantileMethod = iota // The same as Quantile
+	QuantileLinear                          // Linear interpolation between the two samples, like numpy
+	QuantileLower                           // The lower sample
+	QuantileHigher                          // The higher sample
+	QuantileNearest                         // The nearest sample, the even one on ties
+)
+
+// QuantileInterp returns the quantile p of the non-NA elements of the Series
+// computed with the given method. For every method but QuantileEmpirical the
+// quantile lies at position p*(n-1) of the n sorted samples, matching the
+// methods of the same name in numpy and pandas. It returns NaN for String
+// Series, when there are no samples or when p is outside [0, 1].
+func (s Series) QuantileInterp(p float64, method QuantileMethod) float64 {
+	if s.Type() == String || p < 0 || p > 1 {
+		return math.NaN()
+	}
+	var x []float64
+	for _, i := range s.Order(false) {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			x = append(x, e.Float())
+		}
+	}
+	if len(x) == 0 {
+		return math.NaN()
+	}
+
+	h := p * float64(len(x)-1)
+	lo, hi := int(math.Floor(h)), int(math.Ceil(h))
+	switch method {
+	case QuantileEmpirical:
+		return stat.Quantile(p, stat.Empirical, x, nil)
+	case QuantileLinear:
+		return x[lo] + (h-float64(lo))*(x[hi]-x[lo])
+	case QuantileLower:
+		return x[lo]
+	case QuantileHigher:
+		return x[hi]
+	case QuantileNearest:
+		return x[int(math.RoundToEven(h))]
+	default:
+		return math.NaN()
+	}
+}
+
 // Map applies a function matching MapFunction signature, which itself
 // allowing for a fairly flexible MAP implementation, intended for mapping
 // the function over each element in Series and returning a new Series object.
@@ -867,6 +1032,57 @@ func (s Series) Map(f MapFunction) Series {
 	return New(mappedValues, s.Type(), s.Name)
 }
 
+// MapCached works like Map but calls f once per distinct value, reusing the
+// result for every repeated element, which pays off for expensive pure
+// functions over columns with few distinct values. NA elements share a single
+// call as well. f must not depend on anything but the value of its argument.
+func (s Series) MapCached(f MapFunction) Series {
+	cache := make(map[interface{}]Element)
+	mappedValues := make([]Element, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		key := e.Val()
+		value, ok := cache[key]
+		if !ok {
+			value = f(e)
+			cache[key] = value
+		}
+		mappedValues[i] = value
+	}
+	return New(mappedValues, s.Type(), s.Name)
+}
+
+// MapParallel applies f to every element like Map, splitting the elements in
+// contiguous chunks processed by workers goroutines. The result keeps the order
+// of the elements. f is called concurrently, so it must be safe to do so. A
+// non-positive number of workers uses runtime.GOMAXPROCS(0).
+func (s Series) MapParallel(f MapFunction, workers int) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	n := s.Len()
+	if workers > n {
+		workers = n
+	}
+	mappedValues := make([]Element, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		from, to := w*n/workers, (w+1)*n/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := from; i < to; i++ {
+				mappedValues[i] = f(s.elements.Elem(i))
+			}
+		}()
+	}
+	wg.Wait()
+	return New(mappedValues, s.Type(), s.Name)
+}
+
 // Sum calculates the sum value of a series
 func (s Series) Sum() float64 {
 	if s.elements.Len() == 0 || s.Type() == String || s.Type() == Bool {
@@ -881,6 +1097,34 @@ func (s Series) Sum() float64 {
 	return sum
 }
 
+// KahanSum calculates the sum of the values of a series using compensated
+// summation, which stays accurate over long Float series where Sum drifts.
+// NA elements are skipped.
+func (s Series) KahanSum() float64 {
+	if s.elements.Len() == 0 || s.Type() == String || s.Type() == Bool {
+		return math.NaN()
+	}
+	var acc kahanSum
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			acc.add(e.Float())
+		}
+	}
+	return acc.value()
+}
+
+// Prod calculates the product of the values of a series
+func (s Series) Prod() float64 {
+	if s.elements.Len() == 0 || s.Type() == String || s.Type() == Bool {
+		return math.NaN()
+	}
+	prod := 1.0
+	for _, elem := range s.Float() {
+		prod *= elem
+	}
+	return prod
+}
+
 // Slice slices Series from j to k-1 index.
 func (s Series) Slice(j, k int) Series {
 	if s.Err != nil {
@@ -905,7 +1149,13 @@ func (s Series) Slice(j, k int) Series {
 // Two Series are considered equal if they have the same name, type, length,
 // and all elements are equal.
 func (s Series) Equal(other Series) bool {
-	if s.Name != other.Name || s.t != other.t || s.Len() != other.Len() {
+	return s.Name == other.Name && s.EqualValues(other)
+}
+
+// EqualValues compares two Series like Equal but ignores their names, which is
+// handy for Series named automatically by arithmetic operations.
+func (s Series) EqualValues(other Series) bool {
+	if s.t != other.t || s.Len() != other.Len() {
 		return false
 	}
 
@@ -918,6 +1168,31 @@ func (s Series) Equal(other Series) bool {
 	return true
 }
 
+// EqualApprox compares two Series like Equal but lets the elements of Float
+// Series differ by up to tol. Unlike Equal, elements that are NA on both sides
+// are considered equal.
+func (s Series) EqualApprox(other Series, tol float64) bool {
+	if s.Name != other.Name || s.t != other.t || s.Len() != other.Len() {
+		return false
+	}
+	for i := 0; i < s.Len(); i++ {
+		a, b := s.elements.Elem(i), other.elements.Elem(i)
+		switch {
+		case a.IsNA() || b.IsNA():
+			if a.IsNA() != b.IsNA() {
+				return false
+			}
+		case s.t == Float:
+			if math.Abs(a.Float()-b.Float()) > tol {
+				return false
+			}
+		case !a.Eq(b):
+			return false
+		}
+	}
+	return true
+}
+
 // ValuesOptions represents options for the ValuesIterator
 type ValuesOptions struct {
 	Step       int  // Step size for iteration (default: 1)
@@ -1043,6 +1318,17 @@ func NewFromIterator(it iterator, name string) Series {
 	return result
 }
 
+// NewFromChannel drains ch until it is closed into a Series of the given type.
+// Every value is coerced by the element setters of t, so values that can't be
+// converted become NA.
+func NewFromChannel(ch <-chan interface{}, t Type, name string) Series {
+	values := []interface{}{}
+	for v := range ch {
+		values = append(values, v)
+	}
+	return New(values, t, name)
+}
+
 // Number is a constraint that permits any number type
 type Number interface {
 	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | float32 | float64
@@ -1051,28 +1337,59 @@ type Number interface {
 // arithmeticOp defines the signature for arithmetic operations
 type arithmeticOp func(a, b float64) float64
 
+// ArithmeticOption is the type used to configure arithmetic operations
+type ArithmeticOption func(*arithmeticOptions)
+
+type overflowMode int
+
+const (
+	overflowWrap overflowMode = iota
+	overflowSaturate
+	overflowError
+)
+
+type arithmeticOptions struct {
+	overflow overflowMode
+}
+
+// WithSaturate makes Int arithmetic clamp overflowing results to
+// math.MaxInt64 or math.MinInt64 instead of wrapping around.
+func WithSaturate() ArithmeticOption {
+	return func(opts *arithmeticOptions) {
+		opts.overflow = overflowSaturate
+	}
+}
+
+// WithOverflowError makes Int arithmetic set Err on the result when an
+// operation overflows instead of wrapping around.
+func WithOverflowError() ArithmeticOption {
+	return func(opts *arithmeticOptions) {
+		opts.overflow = overflowError
+	}
+}
+
 // Add performs addition with the given value or Series
-func (s Series) Add(value interface{}, name string) Series {
-	return arithmeticOperation(s, value, "add", name)
+func (s Series) Add(value interface{}, name string, opts ...ArithmeticOption) Series {
+	return arithmeticOperation(s, value, "add", name, opts...)
 }
 
 // Sub performs subtraction with the given value or Series
-func (s Series) Sub(value interface{}, name string) Series {
-	return arithmeticOperation(s, value, "sub", name)
+func (s Series) Sub(value interface{}, name string, opts ...ArithmeticOption) Series {
+	return arithmeticOperation(s, value, "sub", name, opts...)
 }
 
 // Mul performs multiplication with the given value or Series
-func (s Series) Mul(value interface{}, name string) Series {
-	return arithmeticOperation(s, value, "mul", name)
+func (s Series) Mul(value interface{}, name string, opts ...ArithmeticOption) Series {
+	return arithmeticOperation(s, value, "mul", name, opts...)
 }
 
 // Div performs division with the given value or Series
-func (s Series) Div(value interface{}, name string) Series {
-	return arithmeticOperation(s, value, "div", name)
+func (s Series) Div(value interface{}, name string, opts ...ArithmeticOption) Series {
+	return arithmeticOperation(s, value, "div", name, opts...)
 }
 
 // performArithmetic is a generic function to perform arithmetic operations
-func performArithmetic(s Series, value interface{}, op string, name string) Series {
+func performArithmetic(s Series, value interface{}, op string, name string, options arithmeticOptions) Series {
 	if s.Type() != Int && s.Type() != Float {
 		s.Err = fmt.Errorf("cannot perform arithmetic operation on series of type %s", s.Type())
 		return s
@@ -1111,7 +1428,7 @@ func performArithmetic(s Series, value interface{}, op string, name string) Seri
 
 	result := New(emptyList, finalType, name)
 	for i := 0; i < s.Len(); i++ {
-		value, err := operator(s.elements.Elem(i).Val(), value, op, finalType)
+		value, err := operator(s.elements.Elem(i).Val(), value, op, finalType, options)
 		if err != nil {
 			s.Err = err
 			return s
@@ -1123,7 +1440,7 @@ func performArithmetic(s Series, value interface{}, op string, name string) Seri
 }
 
 // performSeriesArithmetic performs arithmetic operations between two Series
-func (s Series) performSeriesArithmetic(other Series, op string, name string) Series {
+func (s Series) performSeriesArithmetic(other Series, op string, name string, options arithmeticOptions) Series {
 	if s.Err != nil {
 		return s
 	}
@@ -1163,7 +1480,7 @@ func (s Series) performSeriesArithmetic(other Series, op string, name string) Se
 	result := New(emptyList, finalType, name)
 	// result := s.Copy()
 	for i := 0; i < s.Len(); i++ {
-		value, err := operator(s.elements.Elem(i).Val(), other.elements.Elem(i).Val(), op, finalType)
+		value, err := operator(s.elements.Elem(i).Val(), other.elements.Elem(i).Val(), op, finalType, options)
 		if err != nil {
 			s.Err = err
 			return s
@@ -1173,10 +1490,13 @@ func (s Series) performSeriesArithmetic(other Series, op string, name string) Se
 	return result
 }
 
-func operator(a, b interface{}, op string, finalType Type) (Element, error) {
+func operator(a, b interface{}, op string, finalType Type, options arithmeticOptions) (Element, error) {
 	if finalType != Int && finalType != Float {
 		return nil, fmt.Errorf("cannot perform arithmetic operation between series of different types")
 	}
+	if finalType == Int && options.overflow != overflowWrap {
+		return checkedIntOperator(a, b, op, options.overflow)
+	}
 	// 都转换为float64进行操作，然后根据finalType转换为最终类型
 	var aFloat, bFloat float64
 	var err error
@@ -1231,17 +1551,81 @@ func operator(a, b interface{}, op string, finalType Type) (Element, error) {
 
 }
 
+// checkedIntOperator performs an Int operation with exact integer arithmetic,
+// saturating or failing on overflow depending on mode. NA operands, which Val
+// reports as nil, yield an NA element.
+func checkedIntOperator(a, b interface{}, op string, mode overflowMode) (Element, error) {
+	if a == nil || b == nil {
+		return &intElement{nan: true}, nil
+	}
+	x, err := cast.ToInt64E(a)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %v to int", a)
+	}
+	y, err := cast.ToInt64E(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %v to int", b)
+	}
+
+	var r int64
+	overflow := false
+	// bound is the limit a saturated result is clamped to.
+	var bound int64 = math.MaxInt64
+	switch op {
+	case "add":
+		r = x + y
+		overflow = (y > 0 && x > math.MaxInt64-y) || (y < 0 && x < math.MinInt64-y)
+		if y < 0 {
+			bound = math.MinInt64
+		}
+	case "sub":
+		r = x - y
+		overflow = (y < 0 && x > math.MaxInt64+y) || (y > 0 && x < math.MinInt64+y)
+		if y > 0 {
+			bound = math.MinInt64
+		}
+	case "mul":
+		r = x * y
+		overflow = x != 0 && (r/x != y || (x == -1 && y == math.MinInt64))
+		if (x < 0) != (y < 0) {
+			bound = math.MinInt64
+		}
+	case "div":
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		overflow = x == math.MinInt64 && y == -1
+		if !overflow {
+			r = x / y
+		}
+	default:
+		return nil, fmt.Errorf("unsupported arithmetic operation: %v", op)
+	}
+
+	if overflow {
+		if mode == overflowError {
+			return nil, fmt.Errorf("integer overflow: %d %s %d", x, op, y)
+		}
+		r = bound
+	}
+	return &intElement{e: int(r)}, nil
+}
+
 // arithmeticOperation is a helper function to perform arithmetic operations
-func arithmeticOperation(s Series, value interface{}, op string, name string) Series {
+func arithmeticOperation(s Series, value interface{}, op string, name string, opts ...ArithmeticOption) Series {
 	if s.Err != nil {
 		return s
 	}
+	options := arithmeticOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 
 	switch v := value.(type) {
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		return performArithmetic(s, value, op, name)
+		return performArithmetic(s, value, op, name, options)
 	case Series:
-		return s.performSeriesArithmetic(v, op, name)
+		return s.performSeriesArithmetic(v, op, name, options)
 	default:
 		s.Err = fmt.Errorf("unsupported type for arithmetic operation: %v", reflect.TypeOf(value))
 		return s
@@ -1344,3 +1728,1310 @@ func Min(name string, ss ...Series) Series {
 	}
 	return s
 }
+
+// ToMap returns a sparse representation of the Series mapping the index of every
+// non-NA element to its value.
+func (s Series) ToMap() map[int]interface{} {
+	ret := make(map[int]interface{})
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			continue
+		}
+		ret[i] = e.Val()
+	}
+	return ret
+}
+
+// SeriesFromMap builds a dense Series of the given length from a sparse index to
+// value mapping. Positions not present in the map are filled with NA.
+func SeriesFromMap(m map[int]interface{}, length int, t Type, name string) Series {
+	values := make([]interface{}, length)
+	for i, v := range m {
+		if i < 0 || i >= length {
+			ret := New([]interface{}{}, t, name)
+			ret.Err = fmt.Errorf("series from map: index %d out of range [0, %d)", i, length)
+			return ret
+		}
+		values[i] = v
+	}
+	return New(values, t, name)
+}
+
+// ToColumn returns the Series as a dense typed slice ([]string, []int, []float64
+// or []bool) together with a validity bitmap holding one bit per element in
+// least-significant bit order. A bit set to 0 marks an NA element, whose slot in
+// the values slice holds the zero value of the type. It returns an error if the
+// Series has errors or an unknown type.
+func (s Series) ToColumn() (interface{}, []byte, error) {
+	if err := s.Err; err != nil {
+		return nil, nil, err
+	}
+	n := s.Len()
+	validity := make([]byte, (n+7)/8)
+	for i := 0; i < n; i++ {
+		if !s.elements.Elem(i).IsNA() {
+			validity[i/8] |= 1 << uint(i%8)
+		}
+	}
+	switch s.t {
+	case String:
+		values := make([]string, n)
+		for i, e := range s.elements.(stringElements) {
+			if !e.IsNA() {
+				values[i] = e.e
+			}
+		}
+		return values, validity, nil
+	case Int:
+		values := make([]int, n)
+		for i, e := range s.elements.(intElements) {
+			if !e.IsNA() {
+				values[i] = e.e
+			}
+		}
+		return values, validity, nil
+	case Float:
+		values := make([]float64, n)
+		for i, e := range s.elements.(floatElements) {
+			if !e.IsNA() {
+				values[i] = e.e
+			}
+		}
+		return values, validity, nil
+	case Bool:
+		values := make([]bool, n)
+		for i, e := range s.elements.(boolElements) {
+			if !e.IsNA() {
+				values[i] = e.e
+			}
+		}
+		return values, validity, nil
+	default:
+		return nil, nil, fmt.Errorf("to column: unsupported series type %s", s.t)
+	}
+}
+
+// FromColumn builds a Series from a dense typed slice and a validity bitmap as
+// returned by ToColumn. The type of the Series is derived from the slice type. A
+// nil validity bitmap marks all the elements as valid.
+func FromColumn(values interface{}, validity []byte, name string) Series {
+	var t Type
+	var n int
+	switch v := values.(type) {
+	case []string:
+		t, n = String, len(v)
+	case []int:
+		t, n = Int, len(v)
+	case []float64:
+		t, n = Float, len(v)
+	case []bool:
+		t, n = Bool, len(v)
+	default:
+		ret := New([]int{}, String, name)
+		ret.Err = fmt.Errorf("from column: unsupported values type %T", values)
+		return ret
+	}
+	if validity != nil && len(validity) < (n+7)/8 {
+		ret := New([]int{}, t, name)
+		ret.Err = fmt.Errorf("from column: validity bitmap too short for %d elements", n)
+		return ret
+	}
+	ret := New(values, t, name)
+	if validity == nil {
+		return ret
+	}
+	for i := 0; i < n; i++ {
+		if validity[i/8]&(1<<uint(i%8)) == 0 {
+			ret.elements.Elem(i).Set(nil)
+		}
+	}
+	return ret
+}
+
+// Bitset packs a Bool Series into a bitset of true values and a validity
+// bitset, both holding one bit per element in least-significant bit order. A
+// validity bit set to 0 marks an NA element, whose value bit is left at 0.
+func (s Series) Bitset() ([]uint64, []uint64, error) {
+	if err := s.Err; err != nil {
+		return nil, nil, err
+	}
+	if s.t != Bool {
+		return nil, nil, fmt.Errorf("bitset: unsupported series type %s", s.t)
+	}
+	n := s.Len()
+	values := make([]uint64, (n+63)/64)
+	valid := make([]uint64, (n+63)/64)
+	for i, e := range s.elements.(boolElements) {
+		if e.IsNA() {
+			continue
+		}
+		valid[i/64] |= 1 << uint(i%64)
+		if e.e {
+			values[i/64] |= 1 << uint(i%64)
+		}
+	}
+	return values, valid, nil
+}
+
+// BoolsFromBitset builds a Bool Series of n elements from the bitsets returned
+// by Bitset. A nil validity bitset marks all the elements as valid.
+func BoolsFromBitset(values, valid []uint64, n int, name string) Series {
+	words := (n + 63) / 64
+	if len(values) < words || (valid != nil && len(valid) < words) {
+		ret := New([]bool{}, Bool, name)
+		ret.Err = fmt.Errorf("bools from bitset: bitset too short for %d elements", n)
+		return ret
+	}
+	elements := make(boolElements, n)
+	for i := range elements {
+		if valid != nil && valid[i/64]&(1<<uint(i%64)) == 0 {
+			elements[i].nan = true
+			continue
+		}
+		elements[i].e = values[i/64]&(1<<uint(i%64)) != 0
+	}
+	return Series{Name: name, elements: elements, t: Bool}
+}
+
+// MaskWhere returns a copy of the Series where every element whose counterpart
+// in cond is true has been set to NA. cond must be a Bool Series of the same
+// length; NA elements of cond leave the value unchanged.
+func (s Series) MaskWhere(cond Series) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if err := cond.Err; err != nil {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("mask error: argument has errors: %v", err)
+		return ret
+	}
+	if cond.Type() != Bool {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("mask error: condition must be of type %s, got %s", Bool, cond.Type())
+		return ret
+	}
+	if cond.Len() != s.Len() {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("mask error: dimensions mismatch (%d != %d)", s.Len(), cond.Len())
+		return ret
+	}
+	ret := s.Copy()
+	for i := 0; i < cond.Len(); i++ {
+		c := cond.elements.Elem(i)
+		if c.IsNA() {
+			continue
+		}
+		if b, _ := c.Bool(); b {
+			ret.elements.Elem(i).Set(nil)
+		}
+	}
+	return ret
+}
+
+// FirstValid returns the index and element of the first non-NA entry of the
+// Series, or -1 and nil if all of them are NA.
+func (s Series) FirstValid() (int, Element) {
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			return i, e
+		}
+	}
+	return -1, nil
+}
+
+// LastValid returns the index and element of the last non-NA entry of the
+// Series, or -1 and nil if all of them are NA.
+func (s Series) LastValid() (int, Element) {
+	for i := s.Len() - 1; i >= 0; i-- {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			return i, e
+		}
+	}
+	return -1, nil
+}
+
+// ChangePointsOption is the type used to configure ChangePoints
+type ChangePointsOption func(*changePointsOptions)
+
+type changePointsOptions struct {
+	firstChange bool
+}
+
+// WithFirstChange sets whether the first position is marked as a change.
+func WithFirstChange(b bool) ChangePointsOption {
+	return func(opts *changePointsOptions) {
+		opts.firstChange = b
+	}
+}
+
+// ChangePoints returns a Bool Series marking the positions whose value differs
+// from the previous element. Elements are compared by their exact value, so
+// floats differing beyond the decimals shown by String are a change. Two
+// consecutive NA elements count as no change. The first position is false unless WithFirstChange is
+// set.
+func (s Series) ChangePoints(opts ...ChangePointsOption) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	options := changePointsOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	ret := make([]bool, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if i == 0 {
+			ret[i] = options.firstChange
+			continue
+		}
+		prev, cur := s.elements.Elem(i-1), s.elements.Elem(i)
+		switch {
+		case prev.IsNA() && cur.IsNA():
+			ret[i] = false
+		case prev.IsNA() != cur.IsNA():
+			ret[i] = true
+		default:
+			ret[i] = prev.Val() != cur.Val()
+		}
+	}
+	return New(ret, Bool, s.Name)
+}
+
+// Run is a maximal stretch of consecutive equal elements of a Series. Value
+// is nil for runs of NA elements.
+type Run struct {
+	Value  interface{}
+	Start  int
+	Length int
+}
+
+// Runs enumerates the runs of consecutive equal values of the Series, with
+// elements compared as in ChangePoints. Consecutive NA elements form a single
+// run.
+func (s Series) Runs() []Run {
+	runs := []Run{}
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if n := len(runs); n > 0 {
+			prev := s.elements.Elem(i - 1)
+			if prev.IsNA() == e.IsNA() && (e.IsNA() || prev.Val() == e.Val()) {
+				runs[n-1].Length++
+				continue
+			}
+		}
+		runs = append(runs, Run{Value: e.Val(), Start: i, Length: 1})
+	}
+	return runs
+}
+
+// CumCount returns an Int Series holding, for every element, how many times
+// its value has appeared at or before that position. Values are compared
+// exactly, and NA elements are counted as a value of their own.
+func (s Series) CumCount() Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	ret := make([]int, s.Len())
+	seen := make(map[ElementValue]int)
+	nas := 0
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			nas++
+			ret[i] = nas
+			continue
+		}
+		key := e.Val()
+		seen[key]++
+		ret[i] = seen[key]
+	}
+	return New(ret, Int, s.Name)
+}
+
+// CumNUnique returns an Int Series holding, for every position, the number of
+// distinct values seen at or before it. Values are compared exactly, and NA
+// elements count as a single distinct value on their first appearance.
+func (s Series) CumNUnique() Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	ret := make([]int, s.Len())
+	seen := make(map[ElementValue]bool)
+	sawNA := false
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			sawNA = true
+		} else {
+			seen[e.Val()] = true
+		}
+		ret[i] = len(seen)
+		if sawNA {
+			ret[i]++
+		}
+	}
+	return New(ret, Int, s.Name)
+}
+
+// CumSum returns a Float Series holding the running sum of the Series. NA
+// elements are skipped and stay NA in the result.
+func (s Series) CumSum() Series {
+	return s.cumulate(func(acc, v float64) float64 { return acc + v })
+}
+
+// CumProd returns a Float Series holding the running product of the Series. NA
+// elements are skipped and stay NA in the result.
+func (s Series) CumProd() Series {
+	return s.cumulate(func(acc, v float64) float64 { return acc * v })
+}
+
+// CumMax returns a Float Series holding the running maximum of the Series. NA
+// elements are skipped and stay NA in the result.
+func (s Series) CumMax() Series {
+	return s.cumulate(math.Max)
+}
+
+// CumMin returns a Float Series holding the running minimum of the Series. NA
+// elements are skipped and stay NA in the result.
+func (s Series) CumMin() Series {
+	return s.cumulate(math.Min)
+}
+
+// cumulate folds the valid elements of a numeric Series with f, starting from
+// the first of them, and returns every intermediate value.
+func (s Series) cumulate(f func(acc, v float64) float64) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if !isNumericType(s.t) {
+		return Series{Err: fmt.Errorf("cumulate: unsupported series type %s", s.t)}
+	}
+	ret := make([]float64, s.Len())
+	acc, started := 0.0, false
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			ret[i] = math.NaN()
+			continue
+		}
+		if started {
+			acc = f(acc, e.Float())
+		} else {
+			acc, started = e.Float(), true
+		}
+		ret[i] = acc
+	}
+	return New(ret, Float, s.Name)
+}
+
+// NLargest returns the n largest non-NA elements of the Series in descending
+// order. Ties keep their order of appearance. If n exceeds the number of valid
+// elements all of them are returned.
+func (s Series) NLargest(n int) Series {
+	return s.nOrdered(n, true)
+}
+
+// NSmallest returns the n smallest non-NA elements of the Series in ascending
+// order. Ties keep their order of appearance. If n exceeds the number of valid
+// elements all of them are returned.
+func (s Series) NSmallest(n int) Series {
+	return s.nOrdered(n, false)
+}
+
+func (s Series) nOrdered(n int, reverse bool) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if n < 0 {
+		ret := s.Empty()
+		ret.Err = fmt.Errorf("n must be non-negative, got %d", n)
+		return ret
+	}
+	idx := s.OrderTopK(n, reverse)
+	valid := 0
+	for _, i := range idx {
+		if s.elements.Elem(i).IsNA() {
+			break
+		}
+		valid++
+	}
+	if n > valid {
+		n = valid
+	}
+	return s.Subset(idx[:n])
+}
+
+// Winsorize returns a copy of the Series where values below the lower quantile
+// and above the upper quantile are clipped to those quantiles, as computed by
+// Quantile over the non-NA elements. NA elements are left untouched. lower and
+// upper must satisfy 0 <= lower < upper <= 1.
+func (s Series) Winsorize(lower, upper float64) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if !isNumericType(s.t) {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("winsorize: unsupported series type %s", s.t)
+		return ret
+	}
+	if !(0 <= lower && lower < upper && upper <= 1) {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("winsorize: invalid quantile bounds [%v, %v]", lower, upper)
+		return ret
+	}
+	var valid []int
+	for i := 0; i < s.Len(); i++ {
+		if !s.elements.Elem(i).IsNA() {
+			valid = append(valid, i)
+		}
+	}
+	ret := s.Copy()
+	if len(valid) == 0 {
+		return ret
+	}
+	present := s.Subset(valid)
+	lo, hi := present.Quantile(lower), present.Quantile(upper)
+	for _, i := range valid {
+		e := ret.elements.Elem(i)
+		switch f := e.Float(); {
+		case f < lo:
+			e.Set(lo)
+		case f > hi:
+			e.Set(hi)
+		}
+	}
+	return ret
+}
+
+// Hash returns a stable 64-bit fingerprint of the Series covering its type,
+// name and element values. NA elements hash differently from any value, so two
+// Series hash equally only if they have the same contents in the same order.
+func (s Series) Hash() uint64 {
+	h := fnv.New64a()
+	var buf [binary.MaxVarintLen64]byte
+	write := func(str string) {
+		n := binary.PutUvarint(buf[:], uint64(len(str)))
+		h.Write(buf[:n])
+		h.Write([]byte(str))
+	}
+	write(string(s.t))
+	write(s.Name)
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			h.Write([]byte{0})
+			continue
+		}
+		h.Write([]byte{1})
+		write(fmt.Sprint(e.Val()))
+	}
+	return h.Sum64()
+}
+
+// Pipe applies the given transforms to the Series in order, feeding the result
+// of each one into the next. The chain stops at the first Series with Err set,
+// which is returned as is.
+func (s Series) Pipe(fns ...func(Series) Series) Series {
+	for _, f := range fns {
+		if s.Err != nil {
+			return s
+		}
+		s = f(s)
+	}
+	return s
+}
+
+// ReplaceRegexAll returns a copy of a String Series where every match of the
+// regular expression pattern in each element has been replaced by repl, as
+// with regexp.ReplaceAllString. NA elements are left untouched.
+func (s Series) ReplaceRegexAll(pattern, repl string) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if s.t != String {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("replace regex: unsupported series type %s", s.t)
+		return ret
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("replace regex: %v", err)
+		return ret
+	}
+	ret := s.Copy()
+	for i := 0; i < ret.Len(); i++ {
+		e := ret.elements.Elem(i)
+		if e.IsNA() {
+			continue
+		}
+		e.Set(re.ReplaceAllString(e.String(), repl))
+	}
+	return ret
+}
+
+// PadLeft returns a copy of a String Series where every element shorter than
+// width runes is prefixed with pad up to that width. NA elements stay NA.
+func (s Series) PadLeft(width int, pad rune) Series {
+	return s.pad("pad left", width, pad, true)
+}
+
+// PadRight returns a copy of a String Series where every element shorter than
+// width runes is suffixed with pad up to that width. NA elements stay NA.
+func (s Series) PadRight(width int, pad rune) Series {
+	return s.pad("pad right", width, pad, false)
+}
+
+func (s Series) pad(op string, width int, pad rune, left bool) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if s.t != String {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("%s: unsupported series type %s", op, s.t)
+		return ret
+	}
+	ret := s.Copy()
+	for i := 0; i < ret.Len(); i++ {
+		e := ret.elements.Elem(i)
+		if e.IsNA() {
+			continue
+		}
+		str := e.String()
+		n := width - utf8.RuneCountInString(str)
+		if n <= 0 {
+			continue
+		}
+		if left {
+			e.Set(strings.Repeat(string(pad), n) + str)
+		} else {
+			e.Set(str + strings.Repeat(string(pad), n))
+		}
+	}
+	return ret
+}
+
+// FormatFloat returns a String Series with the elements of a numeric Series
+// formatted with prec decimals. NA elements stay NA.
+func (s Series) FormatFloat(prec int) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if !isNumericType(s.t) {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("format float: unsupported series type %s", s.t)
+		return ret
+	}
+	values := make([]interface{}, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			values[i] = strconv.FormatFloat(e.Float(), 'f', prec, 64)
+		}
+	}
+	return New(values, String, s.Name)
+}
+
+// SearchSortedOption is the type used to configure SearchSorted
+type SearchSortedOption func(*searchSortedOptions)
+
+type searchSortedOptions struct {
+	checkSorted bool
+}
+
+// WithCheckSorted sets whether SearchSorted verifies that the Series is sorted
+// before searching it.
+func WithCheckSorted(b bool) SearchSortedOption {
+	return func(opts *searchSortedOptions) {
+		opts.checkSorted = b
+	}
+}
+
+// SearchSorted returns, for every one of the given values, the index at which
+// it would have to be inserted to keep the Series sorted, like numpy's
+// searchsorted. The values are converted to the type of the Series first. With
+// side "left" the index is the first one whose element is not less than the
+// value, and with "right" the first one whose element is greater. The Series
+// must be sorted ascending with any NA elements at the end, as Sort leaves it;
+// NA values are placed among them.
+func (s Series) SearchSorted(values interface{}, side string, opts ...SearchSortedOption) ([]int, error) {
+	if err := s.Err; err != nil {
+		return nil, err
+	}
+	options := searchSortedOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if side != "left" && side != "right" {
+		return nil, fmt.Errorf("search sorted: unknown side %q", side)
+	}
+	if options.checkSorted {
+		for i := 1; i < s.Len(); i++ {
+			prev, cur := s.elements.Elem(i-1), s.elements.Elem(i)
+			if (prev.IsNA() && !cur.IsNA()) || (!cur.IsNA() && cur.Less(prev)) {
+				return nil, fmt.Errorf("search sorted: series is not sorted at index %d", i)
+			}
+		}
+	}
+	queries := New(values, s.t, "")
+	if err := queries.Err; err != nil {
+		return nil, fmt.Errorf("search sorted: %v", err)
+	}
+	ret := make([]int, queries.Len())
+	for k := 0; k < queries.Len(); k++ {
+		v := queries.elements.Elem(k)
+		ret[k] = sort.Search(s.Len(), func(i int) bool {
+			e := s.elements.Elem(i)
+			switch {
+			case e.IsNA():
+				return side == "left" || !v.IsNA()
+			case v.IsNA():
+				return false
+			case side == "left":
+				return !e.Less(v)
+			default:
+				return e.Greater(v)
+			}
+		})
+	}
+	return ret, nil
+}
+
+// Autocorr returns the Pearson correlation between the Series and itself
+// shifted by lag positions. Pairs where either element is NA are dropped. It
+// returns NaN for non-numeric Series or when fewer than two pairs remain.
+func (s Series) Autocorr(lag int) float64 {
+	if !isNumericType(s.t) {
+		return math.NaN()
+	}
+	if lag < 0 {
+		lag = -lag
+	}
+	var x, y []float64
+	for i := lag; i < s.Len(); i++ {
+		a, b := s.elements.Elem(i-lag), s.elements.Elem(i)
+		if a.IsNA() || b.IsNA() {
+			continue
+		}
+		x = append(x, a.Float())
+		y = append(y, b.Float())
+	}
+	if len(x) < 2 {
+		return math.NaN()
+	}
+	return stat.Correlation(x, y, nil)
+}
+
+// OrderTopK returns the indexes of the first k elements of Order(reverse)
+// without sorting the whole Series, keeping a heap of the k best candidates.
+// NA elements come last by order of appearance, as with Order.
+func (s Series) OrderTopK(k int, reverse bool) []int {
+	if k > s.Len() {
+		k = s.Len()
+	}
+	if k <= 0 {
+		return []int{}
+	}
+	h := topKHeap{reverse: reverse}
+	var nasIdx []int
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			if len(nasIdx) < k {
+				nasIdx = append(nasIdx, i)
+			}
+			continue
+		}
+		ie := indexedElement{i, e}
+		if h.Len() < k {
+			heap.Push(&h, ie)
+		} else if h.before(ie, h.elements[0]) {
+			h.elements[0] = ie
+			heap.Fix(&h, 0)
+		}
+	}
+	ret := make([]int, h.Len(), k)
+	for i := h.Len() - 1; i >= 0; i-- {
+		ret[i] = heap.Pop(&h).(indexedElement).index
+	}
+	return append(ret, nasIdx[:k-len(ret)]...)
+}
+
+// topKHeap is a heap of indexed elements whose root is the one that would be
+// ordered last, so it can be replaced as better candidates show up.
+type topKHeap struct {
+	elements indexedElements
+	reverse  bool
+}
+
+// before reports whether a is ordered before b, ties going to the lowest index.
+func (h topKHeap) before(a, b indexedElement) bool {
+	switch {
+	case !h.reverse && a.element.Less(b.element), h.reverse && b.element.Less(a.element):
+		return true
+	case a.element.Less(b.element), b.element.Less(a.element):
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h topKHeap) Len() int           { return len(h.elements) }
+func (h topKHeap) Less(i, j int) bool { return h.before(h.elements[j], h.elements[i]) }
+func (h topKHeap) Swap(i, j int)      { h.elements.Swap(i, j) }
+func (h *topKHeap) Push(x interface{}) {
+	h.elements = append(h.elements, x.(indexedElement))
+}
+func (h *topKHeap) Pop() interface{} {
+	n := len(h.elements)
+	x := h.elements[n-1]
+	h.elements = h.elements[:n-1]
+	return x
+}
+
+// TrimNA returns the Series without its leading and trailing runs of NA
+// elements. Interior NA elements are kept, and a Series where every element is
+// NA yields an empty Series.
+func (s Series) TrimNA() Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	first, _ := s.FirstValid()
+	if first == -1 {
+		return s.Empty()
+	}
+	last, _ := s.LastValid()
+	return s.Subset(seqRange(first, last+1))
+}
+
+// seqRange returns the integers in [from, to).
+func seqRange(from, to int) []int {
+	ret := make([]int, to-from)
+	for i := range ret {
+		ret[i] = from + i
+	}
+	return ret
+}
+
+// FillMethod selects how FillNA replaces NA elements.
+type FillMethod int
+
+// Supported FillMethods
+const (
+	FillValue       FillMethod = iota // The given value
+	FillForward                       // The previous valid element
+	FillBackward                      // The next valid element
+	FillMean                          // The mean of the valid elements
+	FillMedian                        // The median of the valid elements
+	FillInterpolate                   // Linear interpolation between the surrounding valid elements
+)
+
+// FillNA returns a copy of the Series where NA elements are replaced according
+// to method. value is only used by FillValue. FillForward leaves leading NA
+// elements untouched, FillBackward trailing ones and FillInterpolate both.
+// FillMean, FillMedian and FillInterpolate need a numeric Series, and their
+// results are converted to its type.
+func (s Series) FillNA(method FillMethod, value interface{}) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	ret := s.Copy()
+	fail := func(err error) Series {
+		ret.Err = fmt.Errorf("fillna: %v", err)
+		return ret
+	}
+	if method != FillValue && method != FillForward && method != FillBackward && !isNumericType(s.t) {
+		return fail(fmt.Errorf("unsupported series type %s", s.t))
+	}
+	var valid []int
+	for i := 0; i < s.Len(); i++ {
+		if !s.elements.Elem(i).IsNA() {
+			valid = append(valid, i)
+		}
+	}
+	if len(valid) == s.Len() && method != FillValue {
+		return ret
+	}
+
+	switch method {
+	case FillValue:
+		if value == nil {
+			return fail(fmt.Errorf("nil fill value"))
+		}
+		fill := New([]interface{}{value}, s.t, "")
+		if fill.elements.Elem(0).IsNA() {
+			return fail(fmt.Errorf("fill value %v can't be converted to %s", value, s.t))
+		}
+		for i := 0; i < ret.Len(); i++ {
+			if e := ret.elements.Elem(i); e.IsNA() {
+				e.Set(fill.elements.Elem(0))
+			}
+		}
+	case FillForward:
+		for i := 1; i < ret.Len(); i++ {
+			if e, prev := ret.elements.Elem(i), ret.elements.Elem(i-1); e.IsNA() && !prev.IsNA() {
+				e.Set(prev)
+			}
+		}
+	case FillBackward:
+		for i := ret.Len() - 2; i >= 0; i-- {
+			if e, next := ret.elements.Elem(i), ret.elements.Elem(i+1); e.IsNA() && !next.IsNA() {
+				e.Set(next)
+			}
+		}
+	case FillMean, FillMedian:
+		if len(valid) == 0 {
+			return ret
+		}
+		present := s.Subset(valid)
+		fill := present.Mean()
+		if method == FillMedian {
+			fill = present.Median()
+		}
+		for i := 0; i < ret.Len(); i++ {
+			if e := ret.elements.Elem(i); e.IsNA() {
+				e.Set(fill)
+			}
+		}
+	case FillInterpolate:
+		for k := 1; k < len(valid); k++ {
+			from, to := valid[k-1], valid[k]
+			a, b := s.elements.Elem(from).Float(), s.elements.Elem(to).Float()
+			for i := from + 1; i < to; i++ {
+				ret.elements.Elem(i).Set(a + (b-a)*float64(i-from)/float64(to-from))
+			}
+		}
+	default:
+		return fail(fmt.Errorf("unknown fill method %d", method))
+	}
+	return ret
+}
+
+// SliceByValue returns the contiguous part of a sorted Series whose values fall
+// in [lo, hi]. The boundaries are found with SearchSorted, so the Series must
+// be sorted in ascending order; pass WithCheckSorted to get an error otherwise.
+func (s Series) SliceByValue(lo, hi interface{}, opts ...SearchSortedOption) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	from, err := s.SearchSorted([]interface{}{lo}, "left", opts...)
+	if err != nil {
+		return Series{Err: fmt.Errorf("slice by value: %v", err)}
+	}
+	to, err := s.SearchSorted([]interface{}{hi}, "right")
+	if err != nil {
+		return Series{Err: fmt.Errorf("slice by value: %v", err)}
+	}
+	if to[0] <= from[0] {
+		return s.Empty()
+	}
+	return s.Subset(seqRange(from[0], to[0]))
+}
+
+// ExplodeJSON parses every element of a String Series as a JSON array and
+// returns a String Series with one element per array item, together with the
+// index of the element each of them comes from. String items are kept as is
+// and any other item as its JSON encoding. NA elements, empty arrays and
+// elements that are not JSON arrays yield a single element: NA for the first
+// two and the element itself for the latter.
+func (s Series) ExplodeJSON() (Series, []int) {
+	if err := s.Err; err != nil {
+		return s, nil
+	}
+	if s.t != String {
+		return Series{Err: fmt.Errorf("explode json: unsupported series type %s", s.t)}, nil
+	}
+	var values []interface{}
+	var parents []int
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			values = append(values, nil)
+			parents = append(parents, i)
+			continue
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal([]byte(e.String()), &items); err != nil {
+			values = append(values, e.String())
+			parents = append(parents, i)
+			continue
+		}
+		if len(items) == 0 {
+			values = append(values, nil)
+			parents = append(parents, i)
+			continue
+		}
+		for _, item := range items {
+			var str string
+			if string(item) == "null" {
+				values = append(values, nil)
+			} else if err := json.Unmarshal(item, &str); err == nil {
+				values = append(values, str)
+			} else {
+				values = append(values, string(item))
+			}
+			parents = append(parents, i)
+		}
+	}
+	if values == nil {
+		return s.Empty(), []int{}
+	}
+	return New(values, String, s.Name), parents
+}
+
+// Accumulate folds the elements of a Series from left to right, starting from
+// init, and returns the final accumulator. NA elements are passed to f like any
+// other, so it can decide how to treat them.
+func Accumulate[T any](s Series, init T, f func(acc T, e Element) T) T {
+	acc := init
+	for i := 0; i < s.Len(); i++ {
+		acc = f(acc, s.elements.Elem(i))
+	}
+	return acc
+}
+
+// ShiftByGroup shifts the elements of the Series by periods positions within
+// each group, given the group id of every element. Positive periods move the
+// elements forward, so every element takes the value found periods elements
+// earlier in its own group, and negative periods move them backward. Positions
+// left without a value at the edge of each group are set to fill, or NA if fill
+// is nil. Elements of a group need not be contiguous; their order in the
+// Series is kept.
+func (s Series) ShiftByGroup(groups []int, periods int, fill interface{}) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if len(groups) != s.Len() {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("shift by group: dimensions mismatch (%d != %d)", s.Len(), len(groups))
+		return ret
+	}
+	positions := make(map[int][]int)
+	for i, g := range groups {
+		positions[g] = append(positions[g], i)
+	}
+	values := make([]interface{}, s.Len())
+	for _, idx := range positions {
+		for k, i := range idx {
+			if src := k - periods; src >= 0 && src < len(idx) {
+				values[i] = s.elements.Elem(idx[src]).Val()
+			} else {
+				values[i] = fill
+			}
+		}
+	}
+	return New(values, s.t, s.Name)
+}
+
+// NumberFormat describes how numbers are written in strings, for parsing
+// localized numeric values. A zero rune means the separator is not used.
+//
+// It is applied by ParseNumbers, and by Astype and Set when given the
+// ParseNumberFormat option. Without it, strings are parsed with strconv.
+type NumberFormat struct {
+	Thousands rune // Digit group separator, removed before parsing
+	Decimal   rune // Decimal separator, replaced by '.' before parsing
+}
+
+// Common NumberFormats
+var (
+	USNumberFormat = NumberFormat{Thousands: ',', Decimal: '.'} // 1,234.56
+	EUNumberFormat = NumberFormat{Thousands: '.', Decimal: ','} // 1.234,56
+)
+
+// Normalize rewrites a number written in the format into the form accepted by
+// strconv, dropping the thousands separators and using '.' as the decimal
+// separator. Surrounding spaces are trimmed.
+func (f NumberFormat) Normalize(str string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(str) {
+		switch {
+		case f.Thousands != 0 && r == f.Thousands:
+		case f.Decimal != 0 && r == f.Decimal:
+			b.WriteRune('.')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// formattedNumber is a string holding a number written in the given format.
+// Int and Float elements parse it once normalized.
+type formattedNumber struct {
+	str    string
+	format NumberFormat
+}
+
+// ParseOption is the type used to configure how Astype and Set parse strings
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	// numberFormat is the format of the numbers parsed from String elements
+	// into Int or Float ones. If nil, strings are parsed with strconv.
+	numberFormat *NumberFormat
+}
+
+// ParseNumberFormat sets the numberFormat option for parseOptions.
+func ParseNumberFormat(f NumberFormat) ParseOption {
+	return func(o *parseOptions) {
+		o.numberFormat = &f
+	}
+}
+
+// parseValue returns the value to set on an element of type t from the given
+// element, wrapping String elements bound to a numeric type with the number
+// format, if any.
+func (o parseOptions) parseValue(e Element, t Type) interface{} {
+	if o.numberFormat == nil || e.Type() != String || e.IsNA() || !isNumericType(t) {
+		return e
+	}
+	return formattedNumber{str: e.String(), format: *o.numberFormat}
+}
+
+// ParseNumbers converts a String Series holding numbers written in the given
+// format into an Int or Float Series. Elements that can't be parsed become NA.
+func (s Series) ParseNumbers(t Type, format NumberFormat) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if s.t != String || !isNumericType(t) {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("parse numbers: can't parse %s series as %s", s.t, t)
+		return ret
+	}
+	return s.Astype(t, ParseNumberFormat(format))
+}
+
+// Astype returns a copy of the Series converted to the given type, following
+// the same rules as New. Strings converted to Int or Float are parsed with
+// strconv unless ParseNumberFormat is given, and those that can't be parsed
+// become NA.
+func (s Series) Astype(t Type, opts ...ParseOption) Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	switch t {
+	case String, Int, Float, Bool:
+	default:
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("astype: unknown type %v", t)
+		return ret
+	}
+	cfg := parseOptions{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	values := make([]interface{}, s.Len())
+	for i := range values {
+		values[i] = cfg.parseValue(s.elements.Elem(i), t)
+	}
+	return New(values, t, s.Name)
+}
+
+// MonotonicOption is the type used to configure the monotonicity checks
+type MonotonicOption func(*monotonicOptions)
+
+type monotonicOptions struct {
+	// skipNA ignores NA elements instead of considering the Series not
+	// monotonic.
+	skipNA bool
+	// strict rejects consecutive equal values.
+	strict bool
+}
+
+// MonotonicSkipNA sets the skipNA option for monotonicOptions.
+func MonotonicSkipNA(b bool) MonotonicOption {
+	return func(o *monotonicOptions) {
+		o.skipNA = b
+	}
+}
+
+// MonotonicStrict sets the strict option for monotonicOptions.
+func MonotonicStrict(b bool) MonotonicOption {
+	return func(o *monotonicOptions) {
+		o.strict = b
+	}
+}
+
+// IsMonotonicIncreasing reports whether every element is greater than or
+// equal to the previous one, or strictly greater with MonotonicStrict. A Series
+// holding NA elements is not monotonic unless MonotonicSkipNA is set.
+func (s Series) IsMonotonicIncreasing(opts ...MonotonicOption) bool {
+	return s.isMonotonic(false, opts...)
+}
+
+// IsMonotonicDecreasing reports whether every element is less than or equal
+// to the previous one, or strictly less with MonotonicStrict. A Series holding
+// NA elements is not monotonic unless MonotonicSkipNA is set.
+func (s Series) IsMonotonicDecreasing(opts ...MonotonicOption) bool {
+	return s.isMonotonic(true, opts...)
+}
+
+func (s Series) isMonotonic(decreasing bool, opts ...MonotonicOption) bool {
+	if s.Err != nil {
+		return false
+	}
+	options := monotonicOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	var prev Element
+	for i := 0; i < s.Len(); i++ {
+		e := s.elements.Elem(i)
+		if e.IsNA() {
+			if !options.skipNA {
+				return false
+			}
+			continue
+		}
+		if prev != nil {
+			if decreasing && e.Greater(prev) || !decreasing && e.Less(prev) {
+				return false
+			}
+			if options.strict && e.Eq(prev) {
+				return false
+			}
+		}
+		prev = e
+	}
+	return true
+}
+
+// Align pads the shorter of the Series and other at the end with fill, or NA
+// if fill is nil, so that both have the same length, and returns them in the
+// same order. Both keep their type and name, which makes them ready for
+// element-wise arithmetic.
+func (s Series) Align(other Series, fill interface{}) (Series, Series) {
+	if s.Err != nil || other.Err != nil {
+		return s, other
+	}
+	return s.padTo(other.Len(), fill), other.padTo(s.Len(), fill)
+}
+
+// padTo returns a copy of the Series extended with fill up to n elements.
+func (s Series) padTo(n int, fill interface{}) Series {
+	ret := s.Copy()
+	if s.Len() >= n {
+		return ret
+	}
+	values := make([]interface{}, n-s.Len())
+	for i := range values {
+		values[i] = fill
+	}
+	ret.Append(values)
+	return ret
+}
+
+// WeightedMean returns the mean of a numeric Series weighted by another numeric
+// Series of the same length. Pairs where either the value or the weight is NA
+// are dropped. It returns NaN if the remaining weights add up to zero.
+func (s Series) WeightedMean(weights Series) (float64, error) {
+	if err := s.Err; err != nil {
+		return math.NaN(), err
+	}
+	if err := weights.Err; err != nil {
+		return math.NaN(), fmt.Errorf("weighted mean: weights have errors: %v", err)
+	}
+	if !isNumericType(s.t) || !isNumericType(weights.t) {
+		return math.NaN(), fmt.Errorf("weighted mean: unsupported series types %s and %s", s.t, weights.t)
+	}
+	if s.Len() != weights.Len() {
+		return math.NaN(), fmt.Errorf("weighted mean: dimensions mismatch (%d != %d)", s.Len(), weights.Len())
+	}
+	var sum, total float64
+	for i := 0; i < s.Len(); i++ {
+		v, w := s.elements.Elem(i), weights.elements.Elem(i)
+		if v.IsNA() || w.IsNA() {
+			continue
+		}
+		sum += v.Float() * w.Float()
+		total += w.Float()
+	}
+	if total == 0 {
+		return math.NaN(), nil
+	}
+	return sum / total, nil
+}
+
+// Histogram counts the non-NA values of a numeric Series in bins of equal
+// width between their minimum and maximum. It returns the bins+1 bin edges and
+// the count of every bin; bins are closed on the left except the last one,
+// which also includes the maximum. Like numpy, a Series whose values are all
+// equal, or all NA, yields a single bin spanning [v-0.5, v+0.5] or [0, 1].
+func (s Series) Histogram(bins int) ([]float64, []int, error) {
+	if err := s.Err; err != nil {
+		return nil, nil, err
+	}
+	if !isNumericType(s.t) {
+		return nil, nil, fmt.Errorf("histogram: unsupported series type %s", s.t)
+	}
+	if bins <= 0 {
+		return nil, nil, fmt.Errorf("histogram: bins must be positive, got %d", bins)
+	}
+	var values []float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			f := e.Float()
+			values = append(values, f)
+			lo, hi = math.Min(lo, f), math.Max(hi, f)
+		}
+	}
+	switch {
+	case len(values) == 0:
+		return []float64{0, 1}, []int{0}, nil
+	case lo == hi:
+		return []float64{lo - 0.5, hi + 0.5}, []int{len(values)}, nil
+	}
+
+	width := (hi - lo) / float64(bins)
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = lo + float64(i)*width
+	}
+	edges[bins] = hi
+	counts := make([]int, bins)
+	for _, f := range values {
+		k := int((f - lo) / width)
+		if k >= bins {
+			k = bins - 1
+		}
+		counts[k]++
+	}
+	return edges, counts, nil
+}
+
+// BoolToInt converts a Bool Series into an Int Series holding 1 for true and 0
+// for false, as used in feature matrices. NA elements stay NA.
+func (s Series) BoolToInt() Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if s.t != Bool {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("bool to int: unsupported series type %s", s.t)
+		return ret
+	}
+	return New(s, Int, s.Name)
+}
+
+// IntToBool converts an Int Series into a Bool Series holding true for every
+// non-zero element and false for zeros. NA elements stay NA. Note that New only
+// accepts 0 and 1 when converting integers to booleans.
+func (s Series) IntToBool() Series {
+	if err := s.Err; err != nil {
+		return s
+	}
+	if s.t != Int {
+		ret := s.Copy()
+		ret.Err = fmt.Errorf("int to bool: unsupported series type %s", s.t)
+		return ret
+	}
+	values := make([]interface{}, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if e := s.elements.Elem(i); !e.IsNA() {
+			n, _ := e.Int()
+			values[i] = n != 0
+		}
+	}
+	return New(values, Bool, s.Name)
+}
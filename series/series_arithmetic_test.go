@@ -104,6 +104,66 @@ func TestSeries_Add_IntOverflow(t *testing.T) {
 	assert.Equal(t, 2, result.Val(1))
 }
 
+func TestSeries_Add_IntOverflowSaturate(t *testing.T) {
+	s := New([]int{math.MaxInt64, 1, math.MinInt64}, Int, "test")
+
+	result := s.Add(1, "", WithSaturate())
+	assert.NoError(t, result.Err)
+	assert.Equal(t, Int, result.Type())
+	assert.Equal(t, math.MaxInt64, result.Val(0))
+	assert.Equal(t, 2, result.Val(1))
+
+	result = s.Sub(1, "", WithSaturate())
+	assert.NoError(t, result.Err)
+	assert.Equal(t, math.MinInt64, result.Val(2))
+
+	result = s.Mul(-2, "", WithSaturate())
+	assert.NoError(t, result.Err)
+	assert.Equal(t, math.MinInt64, result.Val(0))
+	assert.Equal(t, -2, result.Val(1))
+	assert.Equal(t, math.MaxInt64, result.Val(2))
+
+	other := New([]int{1, 1, -1}, Int, "other")
+	result = s.Add(other, "", WithSaturate())
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []interface{}{math.MaxInt64, 2, math.MinInt64}, []interface{}{result.Val(0), result.Val(1), result.Val(2)})
+}
+
+func TestSeries_Add_IntOverflowError(t *testing.T) {
+	s := New([]int{math.MaxInt64, 1}, Int, "test")
+
+	result := s.Add(1, "", WithOverflowError())
+	assert.Error(t, result.Err)
+
+	result = New([]int{1, 2}, Int, "test").Add(1, "", WithOverflowError())
+	assert.NoError(t, result.Err)
+	assert.Equal(t, 2, result.Val(0))
+	assert.Equal(t, 3, result.Val(1))
+
+	result = New([]int{math.MinInt64}, Int, "test").Div(-1, "", WithOverflowError())
+	assert.Error(t, result.Err)
+
+	// Float results are not affected by the option.
+	result = s.Add(1.5, "", WithOverflowError())
+	assert.NoError(t, result.Err)
+	assert.Equal(t, Float, result.Type())
+}
+
+func TestSeries_Add_IntOverflowNA(t *testing.T) {
+	s := New([]interface{}{nil, 1}, Int, "test")
+	other := New([]interface{}{1, nil}, Int, "other")
+
+	for _, opt := range []ArithmeticOption{WithSaturate(), WithOverflowError()} {
+		result := s.Add(1, "", opt)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"NaN", "2"}, result.Records())
+
+		result = s.Mul(other, "", opt)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"NaN", "NaN"}, result.Records())
+	}
+}
+
 func TestSeries_Arithmetic(t *testing.T) {
 	tests := []struct {
 		name     string
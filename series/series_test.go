@@ -1164,6 +1164,65 @@ func TestSeries_Concat(t *testing.T) {
 	}
 }
 
+func TestSeries_Concat_TypePromotion(t *testing.T) {
+	tests := []struct {
+		a        Series
+		b        Series
+		expected []float64
+	}{
+		{
+			Ints([]int{1, 2, 3}),
+			Floats([]float64{4.5, 5.5}),
+			[]float64{1, 2, 3, 4.5, 5.5},
+		},
+		{
+			Floats([]float64{0.5, math.NaN()}),
+			Ints([]string{"7", "NaN"}),
+			[]float64{0.5, math.NaN(), 7, math.NaN()},
+		},
+	}
+	for testnum, test := range tests {
+		ab := test.a.Concat(test.b)
+		if err := ab.Err; err != nil {
+			t.Errorf("Test:%v\nError:%v", testnum, err)
+		}
+		if ab.Type() != Float {
+			t.Errorf("Test:%v\nExpected type:\n%v\nReceived:\n%v", testnum, Float, ab.Type())
+		}
+		if err := checkTypes(ab); err != nil {
+			t.Errorf("Test:%v\nError:%v", testnum, err)
+		}
+		received := ab.Float()
+		if len(received) != len(test.expected) {
+			t.Fatalf("Test:%v\nExpected length %v, received %v", testnum, len(test.expected), len(received))
+		}
+		for i := range received {
+			if !compareFloats(test.expected[i], received[i], 6) {
+				t.Errorf(
+					"Test:%v\nExpected:\n%v\nReceived:\n%v",
+					testnum, test.expected, received,
+				)
+				break
+			}
+		}
+	}
+
+	mixed := []struct {
+		a Series
+		b Series
+	}{
+		{Ints([]int{1, 2}), Strings([]string{"a"})},
+		{Floats([]float64{1.5}), Strings([]string{"a"})},
+		{Strings([]string{"a"}), Ints([]int{1, 2})},
+		{Strings([]string{"a"}), Floats([]float64{1.5})},
+	}
+	for testnum, test := range mixed {
+		if err := test.a.Concat(test.b).Err; err == nil {
+			t.Errorf("Test:%v\nExpected error mixing %v and %v", testnum, test.a.Type(), test.b.Type())
+		}
+	}
+}
+
 func TestSeries_Order(t *testing.T) {
 	tests := []struct {
 		series   Series
@@ -1313,6 +1372,10 @@ func TestSeries_Mean(t *testing.T) {
 			Floats([]float64{}),
 			math.NaN(),
 		},
+		{
+			New([]interface{}{1.0, nil, 3.0}, Float, ""),
+			2.0,
+		},
 	}
 
 	for testnum, test := range tests {
@@ -17,6 +17,8 @@ var _ Element = (*floatElement)(nil)
 func (e *floatElement) Set(value interface{}) {
 	e.nan = false
 	switch val := value.(type) {
+	case formattedNumber:
+		e.Set(val.format.Normalize(val.str))
 	case string:
 		if val == "NaN" {
 			e.nan = true
@@ -40,6 +42,10 @@ func (e *floatElement) Set(value interface{}) {
 			e.e = 0
 		}
 	case Element:
+		if val.IsNA() {
+			e.nan = true
+			return
+		}
 		e.e = val.Float()
 	default:
 		e.nan = true
@@ -17,6 +17,8 @@ var _ Element = (*intElement)(nil)
 func (e *intElement) Set(value interface{}) {
 	e.nan = false
 	switch val := value.(type) {
+	case formattedNumber:
+		e.Set(val.format.Normalize(val.str))
 	case string:
 		if val == "NaN" {
 			e.nan = true
@@ -27,6 +27,10 @@ func (e *stringElement) Set(value interface{}) {
 	case int:
 		e.e = strconv.Itoa(val)
 	case float64:
+		if math.IsNaN(val) {
+			e.nan = true
+			return
+		}
 		e.e = strconv.FormatFloat(value.(float64), 'f', 6, 64)
 	case bool:
 		b := value.(bool)
@@ -36,6 +40,10 @@ func (e *stringElement) Set(value interface{}) {
 			e.e = "false"
 		}
 	case Element:
+		if val.IsNA() {
+			e.nan = true
+			return
+		}
 		e.e = val.String()
 	default:
 		e.nan = true
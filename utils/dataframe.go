@@ -796,9 +796,62 @@ func createSeriesFromPath(v reflect.Value, path string, strictMode bool) (series
 			data[i] = nil
 		}
 	}
+	if strictMode {
+		if err := checkConsistentKinds(data, path); err != nil {
+			s := series.Strings("")
+			s.Err = err
+			return s, s.Error()
+		}
+	}
 	return createSeriesFromData(data, path)
 }
 
+// checkConsistentKinds verifies that all the non-nil values extracted for a path
+// share the same shape: either all scalars or all slices, maps or structs.
+func checkConsistentKinds(data []interface{}, path string) error {
+	first := -1
+	var expected string
+	for i, v := range data {
+		kind := valueShape(v)
+		if kind == "" {
+			continue
+		}
+		if first == -1 {
+			first, expected = i, kind
+			continue
+		}
+		if kind != expected {
+			return fmt.Errorf("inconsistent values for path %s: element %d is a %s but element %d is a %s", path, i, kind, first, expected)
+		}
+	}
+	return nil
+}
+
+// valueShape classifies a value as a scalar, slice, map or struct, returning the
+// empty string for nil values.
+func valueShape(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "slice"
+	case reflect.Map:
+		return "map"
+	case reflect.Struct:
+		return "struct"
+	default:
+		return "scalar"
+	}
+}
+
 func createSeriesFromData(data []interface{}, name string) (series.Series, error) {
 	if len(data) == 0 {
 		return series.Series{}, fmt.Errorf("error creating series for path %s: data is empty", name)
@@ -894,8 +947,11 @@ func GetValueByPath(data interface{}, path string) (interface{}, error) {
 		return nil, fmt.Errorf("empty path is not allowed")
 	}
 
+	keys, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
 	v := reflect.ValueOf(data)
-	keys := strings.Split(path, ".")
 	visited := make(map[uintptr]bool)
 
 	for keyIndex, key := range keys {
@@ -996,6 +1052,48 @@ func GetValueByPath(data interface{}, path string) (interface{}, error) {
 	return v.Interface(), nil
 }
 
+// splitPath splits a path into its keys. Keys are separated by dots, except for
+// quoted bracket segments such as `interfaces["eth0.100"].speed`, whose content
+// is taken literally so map keys containing dots can be addressed.
+func splitPath(path string) ([]string, error) {
+	var keys []string
+	var current strings.Builder
+	bracketed := false
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '.':
+			if current.Len() > 0 || !bracketed {
+				keys = append(keys, current.String())
+			}
+			current.Reset()
+			bracketed = false
+		case c == '[' && i+1 < len(path) && (path[i+1] == '"' || path[i+1] == '\''):
+			if current.Len() > 0 {
+				keys = append(keys, current.String())
+				current.Reset()
+			}
+			quote := path[i+1]
+			end := strings.IndexByte(path[i+2:], quote)
+			if end == -1 || i+2+end+1 >= len(path) || path[i+2+end+1] != ']' {
+				return nil, fmt.Errorf("unterminated bracket in path: %s", path)
+			}
+			keys = append(keys, path[i+2:i+2+end])
+			i = i + 2 + end + 1
+			if i+1 < len(path) && path[i+1] != '.' && path[i+1] != '[' {
+				return nil, fmt.Errorf("unexpected character after bracket in path: %s", path)
+			}
+			bracketed = true
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if current.Len() > 0 || !bracketed {
+		keys = append(keys, current.String())
+	}
+	return keys, nil
+}
+
 // Helper function to check if a slice contains a string
 func contains(slice []string, str string) bool {
 	for _, v := range slice {
@@ -1005,3 +1103,180 @@ func contains(slice []string, str string) bool {
 	}
 	return false
 }
+
+// FromStructsOption is the type used to configure FromStructs
+type FromStructsOption func(*fromStructsOptions)
+
+type fromStructsOptions struct {
+	// separator joins the names of nested fields.
+	separator string
+}
+
+// WithSeparator sets the separator option for fromStructsOptions.
+func WithSeparator(sep string) FromStructsOption {
+	return func(c *fromStructsOptions) {
+		c.separator = sep
+	}
+}
+
+// structColumn is a leaf field of a struct type flattened by FromStructs.
+type structColumn struct {
+	name  string
+	index [][]int
+	t     series.Type
+}
+
+// FromStructs builds a DataFrame with one column per field of T. Column names
+// come from the `df` tag, then the `json` tag, then the field name, and a tag
+// of "-" skips the field. Nested structs, and pointers to them, are flattened
+// into dotted columns such as `Address.City`, while embedded structs add their
+// fields without prefix. Structs implementing fmt.Stringer, like time.Time, are
+// kept whole. Column types follow the field kinds: integers become Int, floats
+// Float, bools Bool and anything else String, with slices and maps encoded as
+// JSON. Nil pointers yield NA elements. Recursive struct types, such as a
+// linked list node pointing to the next one, can't be flattened and fail.
+func FromStructs[T any](data []T, opts ...FromStructsOption) (dataframe.DataFrame, error) {
+	cfg := fromStructsOptions{separator: "."}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return dataframe.New(), fmt.Errorf("T must be a struct type")
+	}
+
+	columns, err := structColumns(t, "", nil, cfg.separator, map[reflect.Type]bool{})
+	if err != nil {
+		return dataframe.New(), err
+	}
+	if len(columns) == 0 {
+		return dataframe.New(), fmt.Errorf("struct %s has no exported fields", t)
+	}
+	se := make([]series.Series, len(columns))
+	for j, col := range columns {
+		values := make([]interface{}, len(data))
+		for i := range data {
+			values[i] = structFieldValue(reflect.ValueOf(data[i]), col)
+		}
+		se[j] = series.New(values, col.t, col.name)
+	}
+	df := dataframe.New(se...)
+	return df, df.Error()
+}
+
+// structColumns lists the leaf fields of a struct type, each with the chain of
+// field indexes leading to it, one per dereferenced struct. visiting holds the
+// struct types being flattened on the current path, to reject recursive types.
+func structColumns(t reflect.Type, prefix string, index [][]int, sep string, visiting map[reflect.Type]bool) ([]structColumn, error) {
+	stringer := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	visiting[t] = true
+	defer delete(visiting, t)
+	var columns []structColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		tag := field.Tag.Get("df")
+		if tag == "" {
+			tag = field.Tag.Get("json")
+		}
+		if tagName := strings.Split(tag, ",")[0]; tagName == "-" {
+			continue
+		} else if tagName != "" {
+			name = tagName
+		}
+
+		path := make([][]int, len(index))
+		copy(path, index)
+		if len(path) == 0 {
+			path = append(path, nil)
+		}
+		last := len(path) - 1
+		path[last] = append(append([]int{}, path[last]...), i)
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !ft.Implements(stringer) {
+			nested := prefix + name + sep
+			if field.Anonymous && tag == "" {
+				nested = prefix
+			}
+			if visiting[ft] {
+				return nil, fmt.Errorf("field %s: recursive struct type %s is not supported", prefix+name, ft)
+			}
+			if field.Type.Kind() == reflect.Ptr {
+				path = append(path, nil)
+			}
+			nestedColumns, err := structColumns(ft, nested, path, sep, visiting)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, nestedColumns...)
+			continue
+		}
+		columns = append(columns, structColumn{name: prefix + name, index: path, t: kindType(ft.Kind())})
+	}
+	return columns, nil
+}
+
+// kindType maps a reflect.Kind to the series type FromStructs stores it as.
+func kindType(k reflect.Kind) series.Type {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return series.Int
+	case reflect.Float32, reflect.Float64:
+		return series.Float
+	case reflect.Bool:
+		return series.Bool
+	default:
+		return series.String
+	}
+}
+
+// structFieldValue follows the index chain of a column from a struct value and
+// returns the leaf converted for its column type, or nil behind a nil pointer.
+func structFieldValue(v reflect.Value, col structColumn) interface{} {
+	for _, index := range col.index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch col.t {
+	case series.Int:
+		if v.CanInt() {
+			return int(v.Int())
+		}
+		return int(v.Uint())
+	case series.Float:
+		return v.Float()
+	case series.Bool:
+		return v.Bool()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if v.IsZero() && v.Kind() != reflect.Array {
+			return nil
+		}
+		return toJSON(v.Interface())
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
@@ -215,6 +215,48 @@ func TestGetValueByPathMapAccess(t *testing.T) {
 	}
 }
 
+func TestGetValueByPathBracketKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"interfaces": map[string]interface{}{
+			"eth0.100": map[string]interface{}{
+				"speed": 1000,
+				"ips":   []string{"10.0.0.1", "10.0.0.2"},
+			},
+			"eth1": map[string]interface{}{
+				"speed": 100,
+			},
+		},
+		"a.b": "dotted",
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected interface{}
+		hasError bool
+	}{
+		{"Bracket key containing a dot", `interfaces["eth0.100"].speed`, 1000, false},
+		{"Single quoted bracket key", `interfaces['eth0.100'].speed`, 1000, false},
+		{"Bracket key followed by index", `interfaces["eth0.100"].ips.1`, "10.0.0.2", false},
+		{"Top-level bracket key", `["a.b"]`, "dotted", false},
+		{"Plain dot path still works", "interfaces.eth1.speed", 100, false},
+		{"Unbracketed dotted key is split", "interfaces.eth0.100.speed", nil, true},
+		{"Unterminated bracket", `interfaces["eth0.100.speed`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetValueByPath(data, tt.path)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestGetValueByPathSliceAccess(t *testing.T) {
 	data := map[string]interface{}{
 		"users": []map[string]interface{}{
@@ -860,6 +902,27 @@ func TestFlexibleToDataFrameMixedTypes(t *testing.T) {
 	assert.Equal(t, "true", data[1]["passed"])
 }
 
+func TestFlexibleToDataFrameInconsistentKinds(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "tags": "core"},
+		{"id": 2, "tags": []string{"edge", "lab"}},
+	}
+
+	t.Run("Strict mode reports the offending element", func(t *testing.T) {
+		_, err := FlexibleToDataFrame(data, true, "id", "tags")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "path tags")
+		assert.Contains(t, err.Error(), "element 1 is a slice")
+	})
+
+	t.Run("Non-strict mode keeps coercing", func(t *testing.T) {
+		df, err := FlexibleToDataFrame(data, false, "id", "tags")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, df.Nrow())
+		assert.Equal(t, []string{"core", `["edge","lab"]`}, df.Col("tags").Records())
+	})
+}
+
 func TestFlexibleToDataFrameNestedKeys(t *testing.T) {
 	data := []map[string]interface{}{
 		{
@@ -2093,3 +2156,91 @@ func TestDeepCopy(t *testing.T) {
 		}
 	})
 }
+
+func TestFromStructs(t *testing.T) {
+	type Person struct {
+		Name    string  `json:"name"`
+		Age     int     `json:"age"`
+		Score   float64 `df:"score" json:"points"`
+		Active  bool
+		Tags    []string
+		Ignored string `json:"-"`
+		private int
+	}
+
+	people := []Person{
+		{Name: "Alice", Age: 30, Score: 9.5, Active: true, Tags: []string{"a", "b"}},
+		{Name: "Bob", Age: 25, Score: 7, private: 1},
+	}
+	df, err := FromStructs(people)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "age", "score", "Active", "Tags"}, df.Names())
+	assert.Equal(t, []series.Type{series.String, series.Int, series.Float, series.Bool, series.String}, df.Types())
+	assert.Equal(t, [][]string{
+		{"name", "age", "score", "Active", "Tags"},
+		{"Alice", "30", "9.500000", "true", `["a","b"]`},
+		{"Bob", "25", "7.000000", "false", "NaN"},
+	}, df.Records())
+
+	_, err = FromStructs([]int{1, 2})
+	assert.Error(t, err)
+}
+
+func TestFromStructsNested(t *testing.T) {
+	type Country struct {
+		Code string
+	}
+	type Address struct {
+		City    string
+		Country Country
+	}
+	type Base struct {
+		ID int
+	}
+	type Person struct {
+		Base
+		Name     string
+		Address  Address
+		Previous *Address
+		Since    time.Time
+	}
+
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	people := []Person{
+		{Base: Base{ID: 1}, Name: "Alice", Address: Address{City: "Paris", Country: Country{Code: "FR"}}, Since: since},
+		{Base: Base{ID: 2}, Name: "Bob", Address: Address{City: "Oslo"}, Previous: &Address{City: "Rome"}, Since: since},
+	}
+	df, err := FromStructs(people)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ID", "Name", "Address.City", "Address.Country.Code",
+		"Previous.City", "Previous.Country.Code", "Since",
+	}, df.Names())
+	assert.Equal(t, series.Int, df.Col("ID").Type())
+	assert.Equal(t, []string{"Paris", "Oslo"}, df.Col("Address.City").Records())
+	assert.Equal(t, []string{"FR", ""}, df.Col("Address.Country.Code").Records())
+	assert.Equal(t, []string{"NaN", "Rome"}, df.Col("Previous.City").Records())
+	assert.Equal(t, since.String(), df.Col("Since").Elem(0).String())
+
+	flat, err := FromStructs(people, WithSeparator("_"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Address_Country_Code", flat.Names()[3])
+}
+
+func TestFromStructsRecursive(t *testing.T) {
+	type Node struct {
+		Val  int
+		Next *Node
+	}
+	_, err := FromStructs([]Node{{Val: 1}})
+	assert.Error(t, err)
+
+	type Tree struct {
+		Name  string
+		Child struct {
+			Parent *Tree
+		}
+	}
+	_, err = FromStructs([]Tree{{Name: "root"}})
+	assert.Error(t, err)
+}